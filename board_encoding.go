@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+)
+
+// SetBoardEncoding sets how the board is sent to the passed player in its status
+// messages: messages.BoardMap (default), messages.BoardArray or messages.BoardPacked
+func (b *AcquireDriver) SetBoardEncoding(playerNumber int, encoding string) error {
+	if _, exists := b.players[playerNumber]; !exists {
+		return errors.New(NonexistentPlayer)
+	}
+	switch encoding {
+	case messages.BoardMap, messages.BoardArray, messages.BoardPacked:
+		b.encodings[playerNumber] = encoding
+		return nil
+	}
+	return errors.New(messages.WrongBoard)
+}
+
+// encoded returns the passed status with the board in the encoding chosen by the passed player
+func (b *AcquireDriver) encoded(playerNumber int, status messages.Status) messages.Status {
+	encoding := b.encodings[playerNumber]
+	if encoding == "" || encoding == messages.BoardMap {
+		return status
+	}
+	compact, err := messages.EncodeBoard(status.Board)
+	if err != nil {
+		return status
+	}
+	status.Board = nil
+	if encoding == messages.BoardArray {
+		status.Compact = &compact
+	} else {
+		status.Packed = compact.Pack()
+	}
+	return status
+}
+
+// encodedDelta returns the passed changes with the board in the encoding chosen by
+// the passed player. Compact boards are small enough to be sent whole whenever
+// any of their cells changes.
+func (b *AcquireDriver) encodedDelta(playerNumber int, delta messages.StatusDelta, status messages.Status) messages.StatusDelta {
+	if len(delta.Board) == 0 {
+		return delta
+	}
+	encoded := b.encoded(playerNumber, status)
+	if encoded.Board != nil {
+		return delta
+	}
+	delta.Board = nil
+	delta.Compact, delta.Packed = encoded.Compact, encoded.Packed
+	return delta
+}
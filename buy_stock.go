@@ -1,28 +1,57 @@
 package main
 
 import (
-	"errors"
 	"strconv"
 
 	"github.com/svera/acquire-sackson-driver/internal/corporation"
 	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/tileset"
 	acquireInterfaces "github.com/svera/acquire/interfaces"
 )
 
+// TooManyShares is an error returned when someone tries to buy more shares in a turn than the game allows
+const TooManyShares = "too_many_shares"
+
+// WrongAmount is an error returned when someone tries to buy a negative amount of shares
+const WrongAmount = "wrong_amount"
+
 func (b *AcquireDriver) buyStock(clientName string, params messages.Buy) error {
 	buy := map[acquireInterfaces.Corporation]int{}
+	total := 0
 
 	for corpIndex, amount := range params.CorporationsIndexes {
-		index, _ := strconv.Atoi(corpIndex)
-		if index < 0 || index > 6 {
-			return errors.New(CorporationNotFound)
+		index, err := strconv.Atoi(corpIndex)
+		if err != nil || index < 0 || index > 6 {
+			return newError(CorporationNotFound, "cor", map[string]string{
+				"corporation": corpIndex,
+			})
 		}
 
+		if amount < 0 {
+			return newError(WrongAmount, "cor", map[string]string{
+				"amount": strconv.Itoa(amount),
+			})
+		}
 		buy[b.corporations[index]] = amount
+		total += amount
+	}
+	if total > b.config.MaxBuy {
+		return newError(TooManyShares, "cor", map[string]string{
+			"amount": strconv.Itoa(b.config.MaxBuy),
+		})
 	}
 
+	// The engine settles the game right after the last purchase, so the holdings
+	// needed for the final result are the ones players have once the stock is bought
+	buyer := b.game.CurrentPlayer().Number()
+	holdings := b.holdings()
+	owned := holdings[buyer]
+	for corp, amount := range buy {
+		owned.cash -= amount * corp.StockPrice()
+		owned.shares[corp.(*corporation.Corporation).Index()] += amount
+	}
 	if err := b.game.BuyStock(buy); err != nil {
-		if err.Error() == "no_tiles_available" {
+		if err.Error() == tileset.NoTilesAvailable {
 			b.history = append(b.history, messages.I18n{
 				Key: "game.history.no_tiles_available",
 				Arguments: map[string]string{
@@ -30,9 +59,15 @@ func (b *AcquireDriver) buyStock(clientName string, params messages.Buy) error {
 				},
 			})
 		} else {
-			return err
+			return newError(err.Error(), "cor", nil)
 		}
 	}
+	b.playerStats[buyer].Spent += holdings[buyer].cash - owned.cash
+	for i, amount := range owned.shares {
+		b.playerStats[buyer].SharesBought[i] += amount - holdings[buyer].shares[i]
+	}
+	holdings[buyer] = owned
+	b.settle(holdings)
 	for corp, amount := range buy {
 		if amount > 0 {
 			b.history = append(b.history, messages.I18n{
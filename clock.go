@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/svera/acquire-sackson-driver/internal/bots"
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+	"github.com/svera/sackson-server/api"
+)
+
+// defaultTimeoutBot is the bot level used to play on behalf of players who
+// run out of time, if the game options do not set another one
+const defaultTimeoutBot = "greedy"
+
+// clockEnabled returns true if the game options set a time limit for players
+func (b *AcquireDriver) clockEnabled() bool {
+	return b.options.ActionTime > 0 || b.options.TimeBank > 0
+}
+
+// startClocks fills the time bank of every player and starts timing the first decision
+func (b *AcquireDriver) startClocks() {
+	b.timeBanks = map[int]time.Duration{}
+	for n := range b.players {
+		b.timeBanks[n] = b.options.TimeBank
+	}
+	b.decisionStart = time.Now()
+}
+
+// stopClock charges the time spent in the last decision to the passed player,
+// taking from its time bank whatever exceeds the action time, and starts timing the next one
+func (b *AcquireDriver) stopClock(playerNumber int) {
+	if exceeded := time.Since(b.decisionStart) - b.options.ActionTime; exceeded > 0 {
+		b.timeBanks[playerNumber] -= exceeded
+		if b.timeBanks[playerNumber] < 0 {
+			b.timeBanks[playerNumber] = 0
+		}
+	}
+	b.decisionStart = time.Now()
+}
+
+// clockData returns the time the passed player has left for the current decision,
+// if it is in turn, and in its time bank
+func (b *AcquireDriver) clockData(playerNumber int) *messages.ClockData {
+	if !b.clockEnabled() {
+		return nil
+	}
+	action := b.options.ActionTime
+	bank := b.timeBanks[playerNumber]
+	if b.isCurrentPlayer(playerNumber) && !b.IsGameOver() {
+		elapsed := time.Since(b.decisionStart)
+		if elapsed > action {
+			bank -= elapsed - action
+			if bank < 0 {
+				bank = 0
+			}
+		}
+		action -= elapsed
+		if action < 0 {
+			action = 0
+		}
+	}
+	return &messages.ClockData{
+		Action: int64(action / time.Millisecond),
+		Bank:   int64(bank / time.Millisecond),
+	}
+}
+
+// CheckTimeout plays the current decision on behalf of the player in turn if
+// it has run out of time, using a bot, and returns true if so. If the decision
+// of the bot is rejected, the first legal action is taken instead. It is meant to be
+// called periodically by the server, which, as it is not part of api.Driver, reaches
+// it asserting the driver to interface{ CheckTimeout() (bool, error) }.
+func (b *AcquireDriver) CheckTimeout() (bool, error) {
+	if !b.GameStarted() || b.IsGameOver() || !b.clockEnabled() || b.pendingUndo != nil {
+		return false, nil
+	}
+	n := b.game.CurrentPlayer().Number()
+	if time.Since(b.decisionStart) < b.options.ActionTime+b.timeBanks[n] {
+		return false, nil
+	}
+
+	action, err := b.timeoutAction(n)
+	if err != nil {
+		return false, err
+	}
+	recorded := len(b.log.Entries)
+	if err = b.execute(action); err != nil {
+		// A rejected bot decision must not leave the player in turn stuck forever
+		fallback, ok := b.legalAction(n)
+		if !ok {
+			return false, err
+		}
+		if err = b.execute(fallback); err != nil {
+			return false, err
+		}
+	}
+	b.timeBanks[n] = 0
+	timeout := messages.I18n{
+		Key: "game.history.timeout",
+		Arguments: map[string]string{
+			"player": b.playerName(n),
+		},
+	}
+	b.history = append([]messages.I18n{timeout}, b.history...)
+	b.log.Entries[recorded].History = append([]messages.I18n{timeout}, b.log.Entries[recorded].History...)
+	b.playSubstitutes()
+	return true, nil
+}
+
+// timeoutAction returns the decision a bot would take in place of the passed player
+func (b *AcquireDriver) timeoutAction(playerNumber int) (api.Action, error) {
+	level := b.options.TimeoutBot
+	if level == "" {
+		level = defaultTimeoutBot
+	}
+	ai, err := bots.Create(bots.Params{Level: level}, b.botSeed())
+	if err != nil {
+		return api.Action{}, err
+	}
+	return b.botAction(ai, playerNumber)
+}
+
+// legalAction returns the first of the legal actions of the passed player, if it has any
+func (b *AcquireDriver) legalAction(playerNumber int) (api.Action, bool) {
+	legal, err := b.legalActions(playerNumber)
+	if err != nil {
+		return api.Action{}, false
+	}
+	var params interface{}
+	var actionType string
+	switch b.game.GameStateName() {
+	case acquireInterfaces.PlayTileStateName:
+		if len(legal.Tiles) == 0 {
+			return api.Action{}, false
+		}
+		actionType = messages.TypePlayTile
+		params = messages.PlayTile{Tile: legal.Tiles[0]}
+	case acquireInterfaces.FoundCorpStateName:
+		if len(legal.Corporations) == 0 {
+			return api.Action{}, false
+		}
+		actionType = messages.TypeFoundCorporation
+		params = messages.NewCorp{CorporationIndex: legal.Corporations[0]}
+	case acquireInterfaces.BuyStockStateName:
+		actionType = messages.TypeBuyStock
+		params = messages.Buy{CorporationsIndexes: map[string]int{}}
+	case acquireInterfaces.SellTradeStateName:
+		operations := map[string]messages.SellTradeAmounts{}
+		for corp, amounts := range legal.SellTrade {
+			operations[corp] = amounts[0]
+		}
+		actionType = messages.TypeSellTrade
+		params = messages.SellTrade{CorporationsIndexes: operations}
+	case acquireInterfaces.UntieMergeStateName:
+		if len(legal.Untie) == 0 {
+			return api.Action{}, false
+		}
+		actionType = messages.TypeUntieMerge
+		params = messages.UntieMerge{CorporationIndex: legal.Untie[0]}
+	default:
+		return api.Action{}, false
+	}
+	ser, _ := json.Marshal(params)
+	return api.Action{
+		PlayerName: b.playerName(playerNumber),
+		Type:       actionType,
+		Params:     ser,
+	}, true
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+)
+
+// WrongConfig is an error returned when a game is started with a configuration out of
+// the allowed ranges. The error param is the JSON name of the wrong field.
+const WrongConfig = "wrong_config"
+
+// WrongPlayersNumber is an error returned when a game is started with less or more
+// players than its configuration allows
+const WrongPlayersNumber = "wrong_players_number"
+
+// GameConfig holds the house rules a game is played with. Zero fields take
+// the official rules value. The underlying game engine enforces the official
+// rules, so house rules can only make them stricter where noted.
+type GameConfig struct {
+	// StartingCash is the cash every player starts the game with
+	StartingCash int `json:"csh,omitempty"`
+	// HandSize is the amount of tiles every player starts the game with, from 1 to 12.
+	// A tile is drawn after every one played.
+	HandSize int `json:"hnd,omitempty"`
+	// MaxBuy is the maximum amount of shares which can be bought in a turn, from 1 to 3
+	MaxBuy int `json:"buy,omitempty"`
+	// EndGameCorporationSize is the size a corporation must reach for the end of the
+	// game to be claimed, from 41 to 108
+	EndGameCorporationSize int `json:"end,omitempty"`
+	// SafeCorporationSize is the size from which corporations count as safe when
+	// claiming the end of the game, from 11 to EndGameCorporationSize. Corporations
+	// of 11 tiles or more can not be merged in any case.
+	SafeCorporationSize int `json:"saf,omitempty"`
+	// Corporations is the number of corporations that can be founded, from 2 to 7.
+	// The first ones in the corporations list are used.
+	Corporations int `json:"cor,omitempty"`
+	// MinPlayers and MaxPlayers limit the number of players in the game, from 3 to 6
+	MinPlayers int `json:"min,omitempty"`
+	MaxPlayers int `json:"max,omitempty"`
+}
+
+// DefaultGameConfig returns the configuration of a game played following the official rules
+func DefaultGameConfig() GameConfig {
+	return GameConfig{
+		StartingCash:           6000,
+		HandSize:               6,
+		MaxBuy:                 3,
+		EndGameCorporationSize: 41,
+		SafeCorporationSize:    11,
+		Corporations:           7,
+		MinPlayers:             3,
+		MaxPlayers:             6,
+	}
+}
+
+// withDefaults returns the configuration with its zero fields set to the official rules value
+func (c GameConfig) withDefaults() GameConfig {
+	defaults := DefaultGameConfig()
+	for _, field := range []struct {
+		value        *int
+		defaultValue int
+	}{
+		{&c.StartingCash, defaults.StartingCash},
+		{&c.HandSize, defaults.HandSize},
+		{&c.MaxBuy, defaults.MaxBuy},
+		{&c.EndGameCorporationSize, defaults.EndGameCorporationSize},
+		{&c.SafeCorporationSize, defaults.SafeCorporationSize},
+		{&c.Corporations, defaults.Corporations},
+		{&c.MinPlayers, defaults.MinPlayers},
+		{&c.MaxPlayers, defaults.MaxPlayers},
+	} {
+		if *field.value == 0 {
+			*field.value = field.defaultValue
+		}
+	}
+	return c
+}
+
+// Validate returns an error if a field of the configuration is out of its allowed range
+func (c GameConfig) Validate() error {
+	for _, field := range []struct {
+		name     string
+		value    int
+		min, max int
+	}{
+		{"csh", c.StartingCash, 0, 1000000},
+		{"hnd", c.HandSize, 1, 12},
+		{"buy", c.MaxBuy, 1, 3},
+		{"end", c.EndGameCorporationSize, 41, 108},
+		{"saf", c.SafeCorporationSize, 11, c.EndGameCorporationSize},
+		{"cor", c.Corporations, 2, 7},
+		{"min", c.MinPlayers, 3, 6},
+		{"max", c.MaxPlayers, c.MinPlayers, 6},
+	} {
+		if field.value < field.min || field.value > field.max {
+			return newError(WrongConfig, field.name, nil)
+		}
+	}
+	return nil
+}
+
+// rules returns the house rules bots and clients need to know
+func (c GameConfig) rules() *messages.Rules {
+	return &messages.Rules{
+		MaxBuy:                 c.MaxBuy,
+		EndGameCorporationSize: c.EndGameCorporationSize,
+		SafeCorporationSize:    c.SafeCorporationSize,
+		Corporations:           c.Corporations,
+	}
+}
+
+// applyConfig sets the starting cash and hand of every player of a just started game,
+// drawing the tiles needed or giving back the ones left over
+func (b *AcquireDriver) applyConfig() {
+	for _, n := range b.playerNumbers() {
+		pl := b.players[n]
+		if diff := b.config.StartingCash - pl.Cash(); diff > 0 {
+			pl.AddCash(diff)
+		} else if diff < 0 {
+			pl.RemoveCash(-diff)
+		}
+		// Tiles left over are given back in the same order whatever order they were dealt in
+		hand := append([]acquireInterfaces.Tile{}, pl.Tiles()...)
+		sort.Slice(hand, func(i, j int) bool {
+			return tileToCoords(hand[i]) < tileToCoords(hand[j])
+		})
+		for i := len(hand); i < b.config.HandSize; i++ {
+			tl, err := b.tileset.Draw()
+			if err != nil {
+				break
+			}
+			pl.PickTile(tl)
+		}
+		for i := b.config.HandSize; i < len(hand); i++ {
+			pl.DiscardTile(hand[i])
+			b.tileset.Return(hand[i])
+		}
+	}
+}
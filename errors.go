@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+)
+
+// WrongTile is an error returned when someone tries to play a tile with bad formed coordinates
+const WrongTile = "wrong_tile"
+
+// DriverError describes why the driver rejected an action. Its message is the
+// error code, so it can still be compared against the error constants of this
+// package and the error messages of the acquire library.
+type DriverError struct {
+	// Code identifies the error, as in "corporation_not_found"
+	Code string
+	// Action is the type of the rejected action, as in "buy"
+	Action string
+	// Param is the name of the action parameter which caused the error, if any
+	Param string
+	// Arguments holds the values used to translate the error message
+	Arguments map[string]string
+}
+
+func newError(code string, param string, arguments map[string]string) *DriverError {
+	if arguments == nil {
+		arguments = map[string]string{}
+	}
+	return &DriverError{
+		Code:      code,
+		Param:     param,
+		Arguments: arguments,
+	}
+}
+
+// Error returns the error code
+func (e *DriverError) Error() string {
+	return e.Code
+}
+
+// I18n returns the error in the same format as history entries, so clients can translate it
+func (e *DriverError) I18n() messages.I18n {
+	return messages.I18n{
+		Key:       "game.error." + e.Code,
+		Arguments: e.Arguments,
+	}
+}
+
+// actionError converts an error returned while executing an action into a DriverError
+func actionError(err error, actionType string) *DriverError {
+	driverErr, ok := err.(*DriverError)
+	if !ok {
+		switch err.(type) {
+		case *json.SyntaxError, *json.UnmarshalTypeError:
+			driverErr = newError(WrongMessage, "", nil)
+		default:
+			driverErr = newError(err.Error(), "", nil)
+		}
+	}
+	driverErr.Action = actionType
+	return driverErr
+}
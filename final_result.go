@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/svera/acquire-sackson-driver/internal/corporation"
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+)
+
+// holding stores the cash and shares a player has at some point of the game
+type holding struct {
+	cash   int
+	shares [7]int
+}
+
+// holdings returns the cash and shares every player has now
+func (b *AcquireDriver) holdings() map[int]holding {
+	holdings := map[int]holding{}
+	for n, pl := range b.players {
+		holdings[n] = holding{cash: pl.Cash(), shares: b.playersShares(n)}
+	}
+	return holdings
+}
+
+// finalResult returns the standings of a game which has just reached its end. The
+// engine pays the final majority and minority bonuses and sells all shares at
+// their last price when the game ends, so the passed holdings must be the ones players
+// had right before that. Totals are the cash the engine left to every player, and
+// bonuses whatever it paid apart from the shares sold. Games ended because there are
+// not enough players left are not settled by the engine, so their totals are just the
+// cash of every player plus the value of its shares.
+func (b *AcquireDriver) finalResult(holdings map[int]holding) *messages.FinalResult {
+	result := &messages.FinalResult{
+		Players:      []messages.FinalPlayerData{},
+		Corporations: []messages.FinalCorpData{},
+	}
+	numbers := b.playerNumbers()
+	settled := b.game.GameStateName() == acquireInterfaces.EndGameStateName
+	standings := map[int]*messages.FinalPlayerData{}
+
+	for _, n := range numbers {
+		standings[n] = &messages.FinalPlayerData{
+			Name: b.playerName(n),
+			Cash: holdings[n].cash,
+		}
+	}
+
+	for i, corp := range b.corporations {
+		if corp.Size() == 0 {
+			continue
+		}
+		data := messages.FinalCorpData{
+			Index:    i,
+			Name:     corp.(*corporation.Corporation).Name(),
+			Price:    corp.StockPrice(),
+			Majority: corp.MajorityBonus(),
+			Minority: corp.MinorityBonus(),
+		}
+		for _, n := range numbers {
+			standings[n].Liquidation += holdings[n].shares[i] * corp.StockPrice()
+		}
+		result.Corporations = append(result.Corporations, data)
+	}
+
+	for _, n := range numbers {
+		if settled {
+			standings[n].Total = b.players[n].Cash()
+			standings[n].Bonuses = standings[n].Total - standings[n].Cash - standings[n].Liquidation
+		} else {
+			standings[n].Total = standings[n].Cash + standings[n].Liquidation
+		}
+		result.Players = append(result.Players, *standings[n])
+	}
+	competitors := []messages.FinalPlayerData{}
+	for i, n := range numbers {
+		if !b.isNeutral(n) {
+			competitors = append(competitors, result.Players[i])
+		}
+	}
+	result.Ranking = rank(competitors)
+	if len(b.options.Teams) > 0 {
+		result.TeamRanking = b.teamRanking(result.Players)
+	}
+	return result
+}
+
+// settle stores the final result once the game is over, the passed holdings being
+// the ones players had before the last change in the game
+func (b *AcquireDriver) settle(holdings map[int]holding) {
+	if b.final == nil && b.IsGameOver() {
+		b.final = b.finalResult(holdings)
+	}
+}
+
+// rank sorts players by their final cash, giving the same position to tied players
+func rank(players []messages.FinalPlayerData) []messages.RankData {
+	ranking := make([]messages.RankData, 0, len(players))
+	for _, p := range players {
+		ranking = append(ranking, messages.RankData{Name: p.Name, Total: p.Total})
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return ranking[i].Total > ranking[j].Total
+	})
+	for i := range ranking {
+		if i > 0 && ranking[i].Total == ranking[i-1].Total {
+			ranking[i].Position = ranking[i-1].Position
+		} else {
+			ranking[i].Position = i + 1
+		}
+	}
+	return ranking
+}
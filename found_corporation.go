@@ -1,20 +1,25 @@
 package main
 
 import (
-	"errors"
+	"strconv"
 
 	"github.com/svera/acquire-sackson-driver/internal/corporation"
 	"github.com/svera/acquire-sackson-driver/internal/messages"
 )
 
 func (b *AcquireDriver) foundCorporation(clientName string, params messages.NewCorp) error {
-	if params.CorporationIndex < 0 || params.CorporationIndex > 6 {
-		return errors.New(CorporationNotFound)
+	if params.CorporationIndex < 0 || params.CorporationIndex >= b.config.Corporations {
+		return newError(CorporationNotFound, "cor", map[string]string{
+			"corporation": strconv.Itoa(params.CorporationIndex),
+		})
 	}
 	corp := b.corporations[params.CorporationIndex]
 	if err := b.game.FoundCorporation(corp); err != nil {
-		return err
+		return newError(err.Error(), "cor", map[string]string{
+			"corporation": corp.(*corporation.Corporation).Name(),
+		})
 	}
+	b.playerStats[b.game.CurrentPlayer().Number()].Founded++
 	b.history = append(b.history, messages.I18n{
 		Key: "game.history.founded_corporation",
 		Arguments: map[string]string{
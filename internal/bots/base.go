@@ -2,12 +2,64 @@ package bots
 
 import (
 	"encoding/json"
+	"math/rand"
+	"sort"
+	"strconv"
 
 	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/rules"
+	"github.com/svera/acquire/interfaces"
+	"github.com/svera/sackson-server/api"
 )
 
+const boardLetters = rules.Letters
+
+// defaultRules are the official rules, used when the game status does not include its house rules
+var defaultRules = messages.Rules{
+	MaxBuy:                 3,
+	EndGameCorporationSize: 41,
+	SafeCorporationSize:    11,
+	Corporations:           7,
+}
+
+// rulesOf returns the rules the game in the passed status is played with
+func rulesOf(status messages.Status) messages.Rules {
+	if status.Rules == nil {
+		return defaultRules
+	}
+	return *status.Rules
+}
+
+// Seeder is implemented by bots whose randomness can be fixed after being created
+type Seeder interface {
+	Seed(seed int64)
+}
+
+// strategy is implemented by every bot, and holds the decision taken
+// in each one of the game states
+type strategy interface {
+	claimEndGame() bool
+	playTile() messages.PlayTile
+	foundCorporation() messages.NewCorp
+	buyStock() messages.Buy
+	sellTrade() messages.SellTrade
+	untieMerge() messages.UntieMerge
+}
+
 type base struct {
 	status messages.Status
+	rn     *rand.Rand
+}
+
+func newBase(seed int64) *base {
+	return &base{
+		rn: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Seed resets the random numbers generator used by the bot to the passed seed
+func (b *base) Seed(seed int64) {
+	b.rn = rand.New(rand.NewSource(seed))
 }
 
 func (b *base) FeedGameStatus(message json.RawMessage) error {
@@ -16,6 +68,148 @@ func (b *base) FeedGameStatus(message json.RawMessage) error {
 	if err := json.Unmarshal(message, &content); err != nil {
 		return err
 	}
+	if content.Board == nil && content.Packed != "" {
+		compact, err := messages.UnpackBoard(content.Packed)
+		if err != nil {
+			return err
+		}
+		content.Compact = &compact
+	}
+	if content.Board == nil && content.Compact != nil {
+		board, err := messages.DecodeBoard(*content.Compact)
+		if err != nil {
+			return err
+		}
+		content.Board = board
+	}
 	b.status = content
 	return nil
 }
+
+// play returns a message with the decision taken by the passed strategy
+// for the current game state
+func (b *base) play(s strategy) api.Action {
+	var msg api.Action
+
+	if !b.status.IsLastRound && s.claimEndGame() {
+		msg = api.Action{
+			Type: messages.TypeEndGame,
+		}
+	} else {
+		switch b.status.State {
+		case interfaces.PlayTileStateName:
+			ser, _ := json.Marshal(s.playTile())
+			msg = api.Action{
+				Type:   messages.TypePlayTile,
+				Params: ser,
+			}
+		case interfaces.FoundCorpStateName:
+			ser, _ := json.Marshal(s.foundCorporation())
+			msg = api.Action{
+				Type:   messages.TypeFoundCorporation,
+				Params: ser,
+			}
+		case interfaces.BuyStockStateName:
+			ser, _ := json.Marshal(s.buyStock())
+			msg = api.Action{
+				Type:   messages.TypeBuyStock,
+				Params: ser,
+			}
+		case interfaces.SellTradeStateName:
+			ser, _ := json.Marshal(s.sellTrade())
+			msg = api.Action{
+				Type:   messages.TypeSellTrade,
+				Params: ser,
+			}
+		case interfaces.UntieMergeStateName:
+			ser, _ := json.Marshal(s.untieMerge())
+			msg = api.Action{
+				Type:   messages.TypeUntieMerge,
+				Params: ser,
+			}
+		}
+	}
+
+	return msg
+}
+
+// As the tiles in hand come as a map, we need to store its coordinates in an array
+// before selecting one (only the playable ones). Coordinates are sorted so
+// a seeded bot always takes the same decision.
+func (b *base) tileCoords() []string {
+	coords := make([]string, 0, len(b.status.Hand))
+	for k, playable := range b.status.Hand {
+		if playable {
+			coords = append(coords, k)
+		}
+	}
+	sort.Strings(coords)
+	return coords
+}
+
+func (b *base) hasEnoughCash(amount int, price int) bool {
+	return amount*price < b.status.PlayerInfo.Cash
+}
+
+// endGameConditions returns true if a corporation has reached the end game size
+// or all active corporations are safe
+func (b *base) endGameConditions() bool {
+	game := rulesOf(b.status)
+	sizes := make([]int, len(b.status.Corps))
+	for i, corp := range b.status.Corps {
+		sizes[i] = corp.Size
+	}
+	return rules.EndGameConditions(sizes, game.EndGameCorporationSize, game.SafeCorporationSize)
+}
+
+// stockData returns the data of the corporations the stock purchase rules depend on
+func (b *base) stockData() []rules.Corporation {
+	corps := make([]rules.Corporation, len(b.status.Corps))
+	for i, corp := range b.status.Corps {
+		corps[i] = rules.Corporation{Size: corp.Size, Stock: corp.RemainingShares, Price: corp.Price}
+	}
+	return corps
+}
+
+// acquirer returns the index of the biggest corporation connected to a defunct one
+// through a single cell, which is the one that absorbs it in a merge
+func (b *base) acquirer() int {
+	acquirer := -1
+	for coords := range b.status.Board {
+		if index := b.owner(coords); index == -1 || !b.status.Corps[index].Defunct {
+			continue
+		}
+		for _, cell := range neighbours(coords) {
+			for _, next := range append(neighbours(cell), cell) {
+				index := b.owner(next)
+				if index == -1 || b.status.Corps[index].Defunct {
+					continue
+				}
+				if acquirer == -1 || b.status.Corps[index].Size > b.status.Corps[acquirer].Size ||
+					(b.status.Corps[index].Size == b.status.Corps[acquirer].Size && index < acquirer) {
+					acquirer = index
+				}
+			}
+		}
+	}
+	return acquirer
+}
+
+// neighbours returns the coordinates of the cells orthogonally adjacent to the passed one
+func neighbours(coords string) []string {
+	cells := []string{}
+	for _, c := range rules.ParseCell(coords).Adjacent() {
+		cells = append(cells, c.String())
+	}
+	return cells
+}
+
+// owner returns the index of the corporation which owns the passed cell,
+// or -1 if it does not belong to any
+func (b *base) owner(coords string) int {
+	index, err := strconv.Atoi(b.status.Board[coords])
+	if err != nil {
+		return -1
+	}
+	return index
+}
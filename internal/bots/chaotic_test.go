@@ -0,0 +1,20 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire/interfaces"
+)
+
+func TestChaoticWithoutPlayableTiles(t *testing.T) {
+	bot := NewChaotic(1)
+	bot.status = messages.Status{
+		State: interfaces.PlayTileStateName,
+		Hand:  map[string]bool{"2A": false, "4C": false},
+	}
+
+	if tl := bot.playTile().Tile; tl != "" {
+		t.Errorf("Chaotic bot must not play any tile if none is playable, got %s", tl)
+	}
+}
@@ -2,6 +2,7 @@ package bots
 
 import (
 	"errors"
+	"time"
 
 	"github.com/svera/sackson-server/api"
 )
@@ -11,11 +12,42 @@ const (
 	BotNotFound = "bot_not_found"
 )
 
-// Create returns a new instance of a bot.
-func Create(level string) (api.AI, error) {
-	switch level {
+// Params holds the settings used to create a bot. Iterations and Budget
+// only apply to bots which search for their decisions.
+type Params struct {
+	Level      string
+	Iterations int
+	Budget     time.Duration
+}
+
+// difficulties maps the table difficulties to the bot settings used for them.
+// Iterations and budget set explicitly in the params take precedence over these.
+var difficulties = map[string]Params{
+	"easy":   {Level: "mcts", Iterations: 100},
+	"medium": {Level: "mcts", Iterations: 500},
+	"hard":   {Level: "mcts", Iterations: 3000, Budget: 2 * time.Second},
+}
+
+// Create returns a new instance of a bot, whose random decisions are
+// determined by the passed seed.
+func Create(params Params, seed int64) (api.AI, error) {
+	if difficulty, ok := difficulties[params.Level]; ok {
+		if params.Iterations > 0 {
+			difficulty.Iterations = params.Iterations
+		}
+		if params.Budget > 0 {
+			difficulty.Budget = params.Budget
+		}
+		params = difficulty
+	}
+
+	switch params.Level {
 	case "chaotic":
-		return NewChaotic(), nil
+		return NewChaotic(seed), nil
+	case "greedy":
+		return NewGreedy(seed), nil
+	case "mcts":
+		return NewMCTS(params.Iterations, params.Budget, seed), nil
 	default:
 		return nil, errors.New(BotNotFound)
 	}
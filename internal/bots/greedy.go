@@ -0,0 +1,301 @@
+package bots
+
+import (
+	"strconv"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/rules"
+	"github.com/svera/sackson-server/api"
+)
+
+const (
+	// Value given to a tile which founds a corporation, as it gives a free share
+	foundingValue = 500
+	// Value given to every owned share of a corporation which grows with a tile
+	growthValue = 100
+	// Shares of defunct corporations cheaper than this are kept, hoping
+	// the corporation will be founded again, unless it is the last round
+	holdPrice = 300
+)
+
+// Greedy is a struct which implements an AI which evaluates every decision
+// looking for the most immediate profit: bonuses from merges, majority
+// of shares in the corporations and the best price for defunct stock
+type Greedy struct {
+	*base
+}
+
+// NewGreedy returns a new instance of the greedy AI bot, whose decisions
+// are taken using the passed seed
+func NewGreedy(seed int64) *Greedy {
+	return &Greedy{
+		newBase(seed),
+	}
+}
+
+// Play analyses the current game status and returns a message with the
+// next play movement by the bot AI
+func (r *Greedy) Play() api.Action {
+	return r.play(r)
+}
+
+// claimEndGame only ends the game if the bot is the richest player
+func (r *Greedy) claimEndGame() bool {
+	if !r.endGameConditions() {
+		return false
+	}
+	worth := r.netWorth(r.status.PlayerInfo)
+	for _, rival := range r.status.RivalsInfo {
+		if r.netWorth(rival) > worth {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Greedy) playTile() messages.PlayTile {
+	var best string
+	bestScore := -1
+
+	for _, coords := range r.tileCoords() {
+		if score := r.tileScore(coords); score > bestScore {
+			best = coords
+			bestScore = score
+		}
+	}
+	return messages.PlayTile{
+		Tile: best,
+	}
+}
+
+// tileScore estimates how much money the bot would earn playing the passed tile
+func (r *Greedy) tileScore(coords string) int {
+	corps := r.adjacentCorporations(coords)
+
+	switch {
+	case len(corps) > 1:
+		return r.mergeScore(corps)
+	case len(corps) == 1:
+		return growthValue * r.status.PlayerInfo.OwnedShares[corps[0]]
+	case r.adjacentUnincorporated(coords) && r.foundCorporation().CorporationIndex != -1:
+		return foundingValue
+	}
+	return 0
+}
+
+// mergeScore returns the bonuses the bot would receive from the corporations
+// which would become defunct, that is, all but the biggest one
+func (r *Greedy) mergeScore(corps []int) int {
+	acquirer := corps[0]
+	for _, index := range corps {
+		if r.status.Corps[index].Size > r.status.Corps[acquirer].Size {
+			acquirer = index
+		}
+	}
+
+	score := 0
+	for _, index := range corps {
+		if index != acquirer {
+			score += r.expectedBonus(index)
+		}
+	}
+	return score
+}
+
+// expectedBonus returns the bonus the bot would receive if the passed corporation
+// paid its bonuses with the current shares distribution
+func (r *Greedy) expectedBonus(index int) int {
+	if r.status.PlayerInfo.OwnedShares[index] == 0 {
+		return 0
+	}
+	corp := r.status.Corps[index]
+	// The bot is the first holder, followed by its rivals
+	shares := []int{r.status.PlayerInfo.OwnedShares[index]}
+	for _, rival := range r.status.RivalsInfo {
+		shares = append(shares, rival.OwnedShares[index])
+	}
+
+	bonus := 0
+	majority, minority := rules.Bonuses(shares, corp.MajorityBonus, corp.MinorityBonus)
+	for _, bn := range append(majority, minority...) {
+		if bn.Holder == 0 {
+			bonus += bn.Amount
+		}
+	}
+	return bonus
+}
+
+// rivalsShares returns the highest and the second highest amount of shares
+// of the passed corporation owned by a rival
+func (r *Greedy) rivalsShares(index int) (int, int) {
+	first, second := 0, 0
+	for _, rival := range r.status.RivalsInfo {
+		shares := rival.OwnedShares[index]
+		if shares > first {
+			second = first
+			first = shares
+		} else if shares > second {
+			second = shares
+		}
+	}
+	return first, second
+}
+
+func (r *Greedy) adjacentCorporations(coords string) []int {
+	corps := []int{}
+	found := map[int]bool{}
+	for _, cell := range neighbours(coords) {
+		if index := r.owner(cell); index != -1 && !found[index] {
+			found[index] = true
+			corps = append(corps, index)
+		}
+	}
+	return corps
+}
+
+func (r *Greedy) adjacentUnincorporated(coords string) bool {
+	for _, cell := range neighbours(coords) {
+		if r.status.Board[cell] == "unincorporated" {
+			return true
+		}
+	}
+	return false
+}
+
+// foundCorporation chooses the available corporation in which the bot owns
+// more shares, or the most expensive one if it does not own any.
+// Corporation index will be -1 if there are no corporations available
+func (r *Greedy) foundCorporation() messages.NewCorp {
+	response := messages.NewCorp{CorporationIndex: -1}
+	for i, corp := range r.status.Corps[:rulesOf(r.status).Corporations] {
+		if corp.Size != 0 {
+			continue
+		}
+		best := response.CorporationIndex
+		if best == -1 ||
+			r.status.PlayerInfo.OwnedShares[i] > r.status.PlayerInfo.OwnedShares[best] ||
+			(r.status.PlayerInfo.OwnedShares[i] == r.status.PlayerInfo.OwnedShares[best] &&
+				corporationTier(i, corp) >= corporationTier(best, r.status.Corps[best])) {
+			response.CorporationIndex = i
+		}
+	}
+	return response
+}
+
+// buyStock buys, share by share, the stock which makes the bot get closer to
+// the majority of a corporation, relative to its price
+func (r *Greedy) buyStock() messages.Buy {
+	var bought [7]int
+	buy := map[string]int{}
+	cash := r.status.PlayerInfo.Cash
+
+	for i := 0; i < rulesOf(r.status).MaxBuy; i++ {
+		index := r.bestStock(bought, cash)
+		if index == -1 {
+			break
+		}
+		bought[index]++
+		cash -= r.status.Corps[index].Price
+	}
+	for i, amount := range bought {
+		if amount > 0 {
+			buy[strconv.Itoa(i)] = amount
+		}
+	}
+	return messages.Buy{
+		CorporationsIndexes: buy,
+	}
+}
+
+// bestStock returns the index of the corporation whose next share is the most
+// valuable for the bot, or -1 if none is worth it
+func (r *Greedy) bestStock(bought [7]int, cash int) int {
+	best, bestValue := -1, 0
+
+	for i, corp := range r.status.Corps {
+		if corp.Size == 0 || corp.RemainingShares <= bought[i] || corp.Price > cash {
+			continue
+		}
+		owned := r.status.PlayerInfo.OwnedShares[i] + bought[i]
+		first, _ := r.rivalsShares(i)
+		var value int
+
+		switch {
+		case owned <= first && owned+1 > first:
+			value = corp.MajorityBonus
+		case owned > first && owned-first < 2:
+			value = corp.MajorityBonus / 2
+		case owned+1 == first:
+			value = corp.MinorityBonus / 2
+		}
+		// Safe corporations will not be merged, so their bonuses
+		// will not be paid until the end of the game
+		if corp.Size >= rulesOf(r.status).SafeCorporationSize {
+			value /= 2
+		}
+		if value = value * 100 / corp.Price; value > bestValue {
+			best = i
+			bestValue = value
+		}
+	}
+	return best
+}
+
+// sellTrade trades defunct shares when the acquirer stock is worth more than
+// two of them, sells the rest and keeps them if they are too cheap and the
+// defunct corporation could be founded again
+func (r *Greedy) sellTrade() messages.SellTrade {
+	operations := map[string]messages.SellTradeAmounts{}
+	acquirer := r.acquirer()
+
+	for i, corp := range r.status.Corps {
+		owned := r.status.PlayerInfo.OwnedShares[i]
+		if !corp.Defunct || owned == 0 {
+			continue
+		}
+		amounts := messages.SellTradeAmounts{}
+		if acquirer != -1 && r.status.Corps[acquirer].Price >= 2*corp.Price {
+			pairs := owned / 2
+			if pairs > r.status.Corps[acquirer].RemainingShares {
+				pairs = r.status.Corps[acquirer].RemainingShares
+			}
+			amounts.Trade = pairs * 2
+		}
+		if r.status.IsLastRound || corp.Price >= holdPrice {
+			amounts.Sell = owned - amounts.Trade
+		}
+		operations[strconv.Itoa(i)] = amounts
+	}
+	return messages.SellTrade{
+		CorporationsIndexes: operations,
+	}
+}
+
+// untieMerge makes the corporation in which the bot has the widest lead the acquirer
+func (r *Greedy) untieMerge() messages.UntieMerge {
+	var untieMerge messages.UntieMerge
+	bestLead := 0
+	first := true
+
+	for i, corp := range r.status.Corps {
+		if !corp.Tied {
+			continue
+		}
+		rival, _ := r.rivalsShares(i)
+		if lead := r.status.PlayerInfo.OwnedShares[i] - rival; first || lead > bestLead {
+			untieMerge.CorporationIndex = i
+			bestLead = lead
+			first = false
+		}
+	}
+	return untieMerge
+}
+
+// netWorth returns the cash of the passed player plus the value of its shares
+func (r *Greedy) netWorth(player messages.PlayerData) int {
+	worth := player.Cash
+	for i, corp := range r.status.Corps {
+		worth += player.OwnedShares[i] * corp.Price
+	}
+	return worth
+}
@@ -0,0 +1,59 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+)
+
+func TestGreedyPlaysMergingTileWhenReceivingBonus(t *testing.T) {
+	bot := NewGreedy(1)
+	bot.status = messages.Status{
+		Board: map[string]string{"1A": "0", "2A": "empty", "3A": "1", "9I": "empty"},
+		Hand:  map[string]bool{"2A": true, "9I": true},
+		PlayerInfo: messages.PlayerData{
+			OwnedShares: [7]int{2, 0, 0, 0, 0, 0, 0},
+		},
+	}
+	bot.status.Corps[0] = messages.CorpData{Size: 2, MajorityBonus: 2000, MinorityBonus: 1000}
+	bot.status.Corps[1] = messages.CorpData{Size: 3, MajorityBonus: 3000, MinorityBonus: 1500}
+
+	if tl := bot.playTile().Tile; tl != "2A" {
+		t.Errorf("Greedy bot must play the tile that merges a corporation it has majority in, got %s", tl)
+	}
+}
+
+func TestGreedyTradesWhenAcquirerIsWorthMore(t *testing.T) {
+	bot := NewGreedy(1)
+	bot.status = messages.Status{
+		Board: map[string]string{"1A": "0", "2A": "unincorporated", "3A": "1"},
+		PlayerInfo: messages.PlayerData{
+			OwnedShares: [7]int{5, 0, 0, 0, 0, 0, 0},
+		},
+	}
+	bot.status.Corps[0] = messages.CorpData{Size: 2, Price: 300, Defunct: true}
+	bot.status.Corps[1] = messages.CorpData{Size: 3, Price: 700, RemainingShares: 20}
+
+	amounts := bot.sellTrade().CorporationsIndexes["0"]
+	if amounts.Trade != 4 || amounts.Sell != 1 {
+		t.Errorf("Greedy bot must trade 4 shares and sell 1, got %d traded and %d sold", amounts.Trade, amounts.Sell)
+	}
+}
+
+func TestGreedyExpectsSplitBonusWhenTiedForMajority(t *testing.T) {
+	bot := NewGreedy(1)
+	bot.status = messages.Status{
+		PlayerInfo: messages.PlayerData{
+			OwnedShares: [7]int{2, 0, 0, 0, 0, 0, 0},
+		},
+		RivalsInfo: []messages.PlayerData{
+			{OwnedShares: [7]int{2, 0, 0, 0, 0, 0, 0}},
+			{OwnedShares: [7]int{1, 0, 0, 0, 0, 0, 0}},
+		},
+	}
+	bot.status.Corps[0] = messages.CorpData{Size: 3, MajorityBonus: 3000, MinorityBonus: 1500}
+
+	if bonus := bot.expectedBonus(0); bonus != 2300 {
+		t.Errorf("Greedy bot must expect half of both bonuses rounded up when tied for majority, got %d", bonus)
+	}
+}
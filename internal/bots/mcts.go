@@ -0,0 +1,281 @@
+package bots
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/rules"
+	"github.com/svera/sackson-server/api"
+)
+
+const (
+	// Iterations used when none are set in the bot params
+	defaultIterations = 1000
+	// Maximum number of turns simulated in every playout
+	maxPlayoutTurns = 150
+	// Exploration constant of the UCB1 formula
+	exploration = 1.4
+)
+
+// move is a decision the bot can take in the current game state, along with
+// the results of the playouts in which it was tried
+type move struct {
+	params interface{}
+	apply  func(s *simulation)
+	visits int
+	reward float64
+}
+
+// MCTS is a struct which implements an AI based on flat Monte Carlo search: every
+// possible decision is tried in random playouts of the rest of the game, picked as
+// in a bandit with the UCB1 formula, and the one with the best expected final position
+// is chosen. Only the decisions at hand are evaluated, without growing a tree below them.
+type MCTS struct {
+	*base
+	iterations int
+	budget     time.Duration
+}
+
+// NewMCTS returns a new instance of the Monte Carlo search AI bot, which runs
+// up to the passed number of playouts per decision, stopping earlier if the time
+// budget runs out (unless it is zero), and whose decisions are taken using the passed seed
+func NewMCTS(iterations int, budget time.Duration, seed int64) *MCTS {
+	if iterations <= 0 {
+		iterations = defaultIterations
+	}
+	return &MCTS{
+		base:       newBase(seed),
+		iterations: iterations,
+		budget:     budget,
+	}
+}
+
+// Play analyses the current game status and returns a message with the
+// next play movement by the bot AI
+func (r *MCTS) Play() api.Action {
+	return r.play(r)
+}
+
+// claimEndGame only ends the game if the bot would win it
+func (r *MCTS) claimEndGame() bool {
+	if !r.endGameConditions() {
+		return false
+	}
+	return newSimulation(r.status, r.rn).position(0) == 1
+}
+
+func (r *MCTS) playTile() messages.PlayTile {
+	moves := []*move{}
+	current := newSimulation(r.status, r.rn)
+	for _, coords := range r.tileCoords() {
+		c := parseCell(coords)
+		if !current.playable(c) {
+			continue
+		}
+		moves = append(moves, &move{
+			params: messages.PlayTile{Tile: coords},
+			apply: func(s *simulation) {
+				s.discard(0, c)
+				s.playTile(c, -1)
+				s.endTurn()
+			},
+		})
+	}
+	if len(moves) == 0 {
+		return messages.PlayTile{}
+	}
+	return r.search(moves).(messages.PlayTile)
+}
+
+func (r *MCTS) foundCorporation() messages.NewCorp {
+	moves := []*move{}
+	for i, corp := range r.status.Corps[:rulesOf(r.status).Corporations] {
+		if corp.Size != 0 {
+			continue
+		}
+		index := i
+		moves = append(moves, &move{
+			params: messages.NewCorp{CorporationIndex: index},
+			apply: func(s *simulation) {
+				if c, ok := s.pendingFounding(); ok {
+					s.found(c, index)
+				}
+				s.endTurn()
+			},
+		})
+	}
+	if len(moves) == 0 {
+		return messages.NewCorp{}
+	}
+	return r.search(moves).(messages.NewCorp)
+}
+
+func (r *MCTS) buyStock() messages.Buy {
+	moves := []*move{}
+	for _, combination := range rules.StockCombinations(r.stockData(), rulesOf(r.status).MaxBuy, r.status.PlayerInfo.Cash) {
+		bought := combination
+		buy := map[string]int{}
+		for _, corp := range bought {
+			buy[strconv.Itoa(corp)]++
+		}
+		moves = append(moves, &move{
+			params: messages.Buy{CorporationsIndexes: buy},
+			apply: func(s *simulation) {
+				for _, corp := range bought {
+					s.buy(0, corp)
+				}
+				s.draw(0)
+				s.next()
+			},
+		})
+	}
+	return r.search(moves).(messages.Buy)
+}
+
+// sellTrade tries selling, trading and holding all defunct stock, finishing
+// the merge afterwards as if the bot were the player who started it
+func (r *MCTS) sellTrade() messages.SellTrade {
+	type policy struct {
+		trade bool
+		sell  bool
+	}
+	moves := []*move{}
+	acquirer := r.acquirer()
+
+	for _, p := range []policy{{false, true}, {false, false}, {true, true}, {true, false}} {
+		if p.trade && acquirer == -1 {
+			continue
+		}
+		operations := map[string]messages.SellTradeAmounts{}
+		defunct := []int{}
+		for i, corp := range r.status.Corps {
+			if !corp.Defunct {
+				continue
+			}
+			defunct = append(defunct, i)
+			owned := r.status.PlayerInfo.OwnedShares[i]
+			if owned == 0 {
+				continue
+			}
+			amounts := messages.SellTradeAmounts{}
+			if p.trade {
+				pairs := owned / 2
+				if pairs > r.status.Corps[acquirer].RemainingShares {
+					pairs = r.status.Corps[acquirer].RemainingShares
+				}
+				amounts.Trade = pairs * 2
+			}
+			if p.sell {
+				amounts.Sell = owned - amounts.Trade
+			}
+			operations[strconv.Itoa(i)] = amounts
+		}
+		moves = append(moves, &move{
+			params: messages.SellTrade{CorporationsIndexes: operations},
+			apply: func(s *simulation) {
+				for index, amounts := range operations {
+					corp, _ := strconv.Atoi(index)
+					if amounts.Trade > 0 {
+						s.trade(0, corp, acquirer, amounts.Trade)
+					}
+					s.sell(0, corp, amounts.Sell)
+				}
+				for i := 1; i < len(s.players); i++ {
+					for _, corp := range defunct {
+						s.sell(i, corp, s.players[i].shares[corp])
+					}
+				}
+				if acquirer != -1 {
+					s.absorb(acquirer, defunct)
+				}
+				s.buyRandom(0)
+				s.draw(0)
+				s.next()
+			},
+		})
+	}
+	return r.search(moves).(messages.SellTrade)
+}
+
+func (r *MCTS) untieMerge() messages.UntieMerge {
+	moves := []*move{}
+	for i, corp := range r.status.Corps {
+		if !corp.Tied {
+			continue
+		}
+		acquirer := i
+		defunct := []int{}
+		for j, other := range r.status.Corps {
+			if j != acquirer && (other.Tied || other.Defunct) {
+				defunct = append(defunct, j)
+			}
+		}
+		moves = append(moves, &move{
+			params: messages.UntieMerge{CorporationIndex: acquirer},
+			apply: func(s *simulation) {
+				for _, corp := range defunct {
+					s.payBonuses(corp)
+					for i := range s.players {
+						s.sell(i, corp, s.players[i].shares[corp])
+					}
+				}
+				s.absorb(acquirer, defunct)
+				s.endTurn()
+			},
+		})
+	}
+	if len(moves) == 0 {
+		return messages.UntieMerge{}
+	}
+	return r.search(moves).(messages.UntieMerge)
+}
+
+// search runs the playouts, choosing which move to try in each one using UCB1,
+// and returns the params of the move with the best average result
+func (r *MCTS) search(moves []*move) interface{} {
+	if len(moves) == 1 {
+		return moves[0].params
+	}
+	var deadline time.Time
+	if r.budget > 0 {
+		deadline = time.Now().Add(r.budget)
+	}
+
+	for i := 0; i < r.iterations; i++ {
+		if r.budget > 0 && time.Now().After(deadline) {
+			break
+		}
+		m := r.selectMove(moves, i)
+		s := newSimulation(r.status, r.rn)
+		m.apply(s)
+		s.playout(maxPlayoutTurns)
+		m.visits++
+		m.reward += float64(len(s.players)-s.position(0)) / float64(len(s.players)-1)
+	}
+
+	best := moves[0]
+	for _, m := range moves {
+		if m.visits > 0 && (best.visits == 0 || m.reward/float64(m.visits) > best.reward/float64(best.visits)) {
+			best = m
+		}
+	}
+	return best.params
+}
+
+func (r *MCTS) selectMove(moves []*move, iteration int) *move {
+	var selected *move
+	bestValue := math.Inf(-1)
+	for _, m := range moves {
+		if m.visits == 0 {
+			return m
+		}
+		value := m.reward/float64(m.visits) + exploration*math.Sqrt(math.Log(float64(iteration))/float64(m.visits))
+		if value > bestValue {
+			selected = m
+			bestValue = value
+		}
+	}
+	return selected
+}
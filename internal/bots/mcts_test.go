@@ -0,0 +1,59 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire/interfaces"
+)
+
+func TestMCTSPlaysOneOfItsTiles(t *testing.T) {
+	bot := NewMCTS(50, 0, 1)
+	bot.status = messages.Status{
+		State: interfaces.PlayTileStateName,
+		Board: map[string]string{"1A": "unincorporated", "5C": "0", "6C": "0"},
+		Hand:  map[string]bool{"2A": true, "4C": true, "9I": true},
+		PlayerInfo: messages.PlayerData{
+			Cash: 6000,
+		},
+		RivalsInfo: []messages.PlayerData{{Cash: 6000}, {Cash: 6000}},
+	}
+	for i := range bot.status.Corps {
+		bot.status.Corps[i].RemainingShares = 25
+	}
+	bot.status.Corps[0] = messages.CorpData{Size: 2, Price: 200, RemainingShares: 25}
+
+	if tl := bot.playTile().Tile; !bot.status.Hand[tl] {
+		t.Errorf("MCTS bot must play one of the tiles in its hand, got %s", tl)
+	}
+}
+
+func TestCreateDifficultyKeepsExplicitParams(t *testing.T) {
+	ai, err := Create(Params{Level: "hard", Iterations: 10}, 1)
+	if err != nil {
+		t.Fatalf("Creating a bot of a known difficulty must not return an error, got %v", err)
+	}
+	if bot := ai.(*MCTS); bot.iterations != 10 || bot.budget != difficulties["hard"].Budget {
+		t.Errorf("Explicit params must take precedence over the difficulty ones, got %d iterations and %v", bot.iterations, bot.budget)
+	}
+}
+
+func TestMCTSDoesNotPlayFoundingTileWithoutCorporationsLeft(t *testing.T) {
+	bot := NewMCTS(50, 0, 1)
+	bot.status = messages.Status{
+		State: interfaces.PlayTileStateName,
+		Board: map[string]string{"1A": "unincorporated"},
+		Hand:  map[string]bool{"2A": true, "9I": true},
+		PlayerInfo: messages.PlayerData{
+			Cash: 6000,
+		},
+		RivalsInfo: []messages.PlayerData{{Cash: 6000}, {Cash: 6000}},
+	}
+	for i := range bot.status.Corps {
+		bot.status.Corps[i] = messages.CorpData{Size: 2, Price: 200, RemainingShares: 25}
+	}
+
+	if tl := bot.playTile().Tile; tl != "9I" {
+		t.Errorf("MCTS bot must not play a tile founding a corporation when there are none left, got %s", tl)
+	}
+}
@@ -0,0 +1,484 @@
+package bots
+
+import (
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/svera/acquire-sackson-driver/internal/corporation"
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/rules"
+)
+
+const (
+	cellEmpty          = -2
+	cellUnincorporated = -1
+	boardNumbers       = rules.Numbers
+	handSize           = 6
+)
+
+// cell stores the position of a tile on the board, both values starting at zero
+type cell struct {
+	number int
+	letter int
+}
+
+func parseCell(coords string) cell {
+	c := rules.ParseCell(coords)
+	return cell{c.Number - 1, c.Letter}
+}
+
+func (c cell) String() string {
+	return rules.Cell{Number: c.number + 1, Letter: c.letter}.String()
+}
+
+type simPlayer struct {
+	cash   int
+	shares [7]int
+	hand   []cell
+}
+
+// simulation is a simplified model of an Acquire game, built from a status message,
+// which bots use to play out the rest of a game. Unknown information, as the rivals'
+// hands and the order of the tiles yet to be drawn, is sampled randomly.
+// The bot is always the first player of the simulation.
+type simulation struct {
+	board   [boardNumbers][len(boardLetters)]int
+	size    [7]int
+	stock   [7]int
+	tier    [7]corporation.Tier
+	players []simPlayer
+	current int
+	bag     []cell
+	rules   messages.Rules
+	rn      *rand.Rand
+}
+
+// corporationTier returns the tier reported for a corporation, guessing it from
+// its price or its position in the corporations list if it is not reported
+func corporationTier(index int, corp messages.CorpData) corporation.Tier {
+	if tier, err := corporation.ParseTier(corp.Tier); err == nil {
+		return tier
+	}
+	if corp.Size >= 2 && corp.Price > 0 {
+		tier := corporation.Tier((corp.Price - corporation.StockPrice(corp.Size, corporation.Cheap)) / 100)
+		if tier >= corporation.Cheap && tier <= corporation.Expensive {
+			return tier
+		}
+	}
+	switch {
+	case index < 2:
+		return corporation.Cheap
+	case index < 5:
+		return corporation.Medium
+	}
+	return corporation.Expensive
+}
+
+func newSimulation(status messages.Status, rn *rand.Rand) *simulation {
+	s := &simulation{rules: rulesOf(status), rn: rn}
+	known := map[cell]bool{}
+
+	for number := 0; number < boardNumbers; number++ {
+		for letter := range boardLetters {
+			c := cell{number, letter}
+			switch value := status.Board[c.String()]; value {
+			case "", "empty":
+				s.board[number][letter] = cellEmpty
+			case "unincorporated":
+				s.board[number][letter] = cellUnincorporated
+				known[c] = true
+			default:
+				s.board[number][letter], _ = strconv.Atoi(value)
+				known[c] = true
+			}
+		}
+	}
+	for i, corp := range status.Corps {
+		s.size[i] = corp.Size
+		s.stock[i] = corp.RemainingShares
+		s.tier[i] = corporationTier(i, corp)
+	}
+
+	bot := simPlayer{cash: status.PlayerInfo.Cash, shares: status.PlayerInfo.OwnedShares}
+	coords := make([]string, 0, len(status.Hand))
+	for k := range status.Hand {
+		coords = append(coords, k)
+	}
+	sort.Strings(coords)
+	for _, k := range coords {
+		bot.hand = append(bot.hand, parseCell(k))
+		known[parseCell(k)] = true
+	}
+	s.players = append(s.players, bot)
+	for _, rival := range status.RivalsInfo {
+		s.players = append(s.players, simPlayer{cash: rival.Cash, shares: rival.OwnedShares})
+	}
+
+	for number := 0; number < boardNumbers; number++ {
+		for letter := range boardLetters {
+			if c := (cell{number, letter}); !known[c] {
+				s.bag = append(s.bag, c)
+			}
+		}
+	}
+	for i := len(s.bag) - 1; i > 0; i-- {
+		j := rn.Intn(i + 1)
+		s.bag[i], s.bag[j] = s.bag[j], s.bag[i]
+	}
+	for i := 1; i < len(s.players); i++ {
+		for j := 0; j < handSize; j++ {
+			s.draw(i)
+		}
+	}
+	return s
+}
+
+func (s *simulation) price(corp int) int {
+	return corporation.StockPrice(s.size[corp], s.tier[corp])
+}
+
+func (s *simulation) neighbours(c cell) []cell {
+	cells := []cell{}
+	for _, n := range (rules.Cell{Number: c.number + 1, Letter: c.letter}).Adjacent() {
+		cells = append(cells, cell{n.Number - 1, n.Letter})
+	}
+	return cells
+}
+
+func (s *simulation) owner(c cell) int {
+	return s.board[c.number][c.letter]
+}
+
+func (s *simulation) adjacentCorporations(c cell) []int {
+	corps := []int{}
+	found := map[int]bool{}
+	for _, n := range s.neighbours(c) {
+		if corp := s.owner(n); corp >= 0 && !found[corp] {
+			found[corp] = true
+			corps = append(corps, corp)
+		}
+	}
+	return corps
+}
+
+func (s *simulation) adjacentUnincorporated(c cell) bool {
+	for _, n := range s.neighbours(c) {
+		if s.owner(n) == cellUnincorporated {
+			return true
+		}
+	}
+	return false
+}
+
+// available returns the corporations which can be founded
+func (s *simulation) available() []int {
+	corps := []int{}
+	for i, size := range s.size[:s.rules.Corporations] {
+		if size == 0 {
+			corps = append(corps, i)
+		}
+	}
+	return corps
+}
+
+func (s *simulation) playable(c cell) bool {
+	corps := s.adjacentCorporations(c)
+	safe := 0
+	for _, corp := range corps {
+		if s.size[corp] >= s.rules.SafeCorporationSize {
+			safe++
+		}
+	}
+	if safe > 1 {
+		return false
+	}
+	if len(corps) == 0 && s.adjacentUnincorporated(c) && len(s.available()) == 0 {
+		return false
+	}
+	return true
+}
+
+// playTile places a tile on the board and resolves its consequences. A random
+// corporation is founded if found is -1 and the tile starts a new one, the tile
+// being left unincorporated if there are none left to be founded.
+func (s *simulation) playTile(c cell, found int) {
+	corps := s.adjacentCorporations(c)
+	switch {
+	case len(corps) > 1:
+		s.merge(c, corps)
+	case len(corps) == 1:
+		s.fill(c, corps[0], map[int]bool{cellUnincorporated: true})
+	case s.adjacentUnincorporated(c):
+		if found == -1 {
+			available := s.available()
+			if len(available) == 0 {
+				s.board[c.number][c.letter] = cellUnincorporated
+				break
+			}
+			found = available[s.rn.Intn(len(available))]
+		}
+		s.found(c, found)
+	default:
+		s.board[c.number][c.letter] = cellUnincorporated
+	}
+	s.recount()
+}
+
+func (s *simulation) found(c cell, corp int) {
+	s.fill(c, corp, map[int]bool{cellUnincorporated: true})
+	s.recount()
+	if s.stock[corp] > 0 {
+		s.stock[corp]--
+		s.players[s.current].shares[corp]++
+	}
+}
+
+// merge absorbs all corporations adjacent to the tile into the biggest one, paying
+// their bonuses. In playouts, shareholders always sell their defunct stock.
+func (s *simulation) merge(c cell, corps []int) {
+	acquirer := corps[s.rn.Intn(len(corps))]
+	for _, corp := range corps {
+		if s.size[corp] > s.size[acquirer] {
+			acquirer = corp
+		}
+	}
+	absorbed := map[int]bool{cellUnincorporated: true}
+	for _, corp := range corps {
+		if corp == acquirer {
+			continue
+		}
+		absorbed[corp] = true
+		s.payBonuses(corp)
+		for i := range s.players {
+			s.sell(i, corp, s.players[i].shares[corp])
+		}
+	}
+	s.fill(c, acquirer, absorbed)
+}
+
+// absorb merges the defunct corporations into the acquirer when the merging tile
+// is not known, as happens with merges already in progress in the real game
+func (s *simulation) absorb(acquirer int, defunct []int) {
+	absorbed := map[int]bool{}
+	for _, corp := range defunct {
+		absorbed[corp] = true
+	}
+	for number := range s.board {
+		for letter := range s.board[number] {
+			if absorbed[s.board[number][letter]] {
+				s.board[number][letter] = acquirer
+			}
+		}
+	}
+	for number := range s.board {
+		for letter := range s.board[number] {
+			if s.board[number][letter] == acquirer {
+				s.fill(cell{number, letter}, acquirer, map[int]bool{cellUnincorporated: true})
+			}
+		}
+	}
+	s.recount()
+}
+
+// fill assigns the passed cell, and all cells connected to it whose owner
+// is one of the absorbed ones, to the passed owner
+func (s *simulation) fill(c cell, owner int, absorbed map[int]bool) {
+	pending := []cell{c}
+	s.board[c.number][c.letter] = owner
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		for _, n := range s.neighbours(current) {
+			if absorbed[s.owner(n)] {
+				s.board[n.number][n.letter] = owner
+				pending = append(pending, n)
+			}
+		}
+	}
+}
+
+func (s *simulation) recount() {
+	s.size = [7]int{}
+	for number := range s.board {
+		for letter := range s.board[number] {
+			if corp := s.board[number][letter]; corp >= 0 {
+				s.size[corp]++
+			}
+		}
+	}
+}
+
+// bonuses returns the bonus each player would receive from the passed corporation,
+// following the same rules as the driver
+func (s *simulation) bonuses(corp int) []int {
+	amounts := make([]int, len(s.players))
+	shares := make([]int, len(s.players))
+	for i, p := range s.players {
+		shares[i] = p.shares[corp]
+	}
+	majority, minority := rules.Bonuses(shares, s.price(corp)*10, s.price(corp)*5)
+	for _, bn := range append(majority, minority...) {
+		amounts[bn.Holder] += bn.Amount
+	}
+	return amounts
+}
+
+func (s *simulation) payBonuses(corp int) {
+	for i, amount := range s.bonuses(corp) {
+		s.players[i].cash += amount
+	}
+}
+
+func (s *simulation) sell(player int, corp int, amount int) {
+	s.players[player].cash += amount * s.price(corp)
+	s.players[player].shares[corp] -= amount
+	s.stock[corp] += amount
+}
+
+// trade exchanges two shares of the defunct corporation for every share of the acquirer
+func (s *simulation) trade(player int, defunct int, acquirer int, amount int) {
+	s.players[player].shares[defunct] -= amount
+	s.stock[defunct] += amount
+	s.players[player].shares[acquirer] += amount / 2
+	s.stock[acquirer] -= amount / 2
+}
+
+func (s *simulation) buy(player int, corp int) bool {
+	price := s.price(corp)
+	if s.size[corp] == 0 || s.stock[corp] == 0 || price > s.players[player].cash {
+		return false
+	}
+	s.players[player].cash -= price
+	s.players[player].shares[corp]++
+	s.stock[corp]--
+	return true
+}
+
+func (s *simulation) buyRandom(player int) {
+	for i := 0; i < s.rules.MaxBuy; i++ {
+		corp := s.rn.Intn(len(s.size))
+		s.buy(player, corp)
+	}
+}
+
+func (s *simulation) draw(player int) {
+	if len(s.bag) == 0 {
+		return
+	}
+	s.players[player].hand = append(s.players[player].hand, s.bag[0])
+	s.bag = s.bag[1:]
+}
+
+func (s *simulation) discard(player int, c cell) {
+	hand := s.players[player].hand
+	for i := range hand {
+		if hand[i] == c {
+			s.players[player].hand = append(hand[:i:i], hand[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *simulation) next() {
+	s.current = (s.current + 1) % len(s.players)
+}
+
+// endTurn buys random stock for the current player and passes the turn
+func (s *simulation) endTurn() {
+	s.buyRandom(s.current)
+	s.draw(s.current)
+	s.next()
+}
+
+// pendingFounding returns a cell of the biggest group of unincorporated cells,
+// which is the one that will become a corporation when it is founded
+func (s *simulation) pendingFounding() (cell, bool) {
+	var biggest cell
+	biggestSize := 1
+	visited := map[cell]bool{}
+
+	for number := range s.board {
+		for letter := range s.board[number] {
+			c := cell{number, letter}
+			if s.owner(c) != cellUnincorporated || visited[c] {
+				continue
+			}
+			size := 0
+			pending := []cell{c}
+			visited[c] = true
+			for len(pending) > 0 {
+				current := pending[0]
+				pending = pending[1:]
+				size++
+				for _, n := range s.neighbours(current) {
+					if s.owner(n) == cellUnincorporated && !visited[n] {
+						visited[n] = true
+						pending = append(pending, n)
+					}
+				}
+			}
+			if size > biggestSize {
+				biggest = c
+				biggestSize = size
+			}
+		}
+	}
+	return biggest, biggestSize > 1
+}
+
+// turn plays a random turn for the current player, returning false
+// if that player had no tile to play
+func (s *simulation) turn() bool {
+	playable := []cell{}
+	for _, c := range s.players[s.current].hand {
+		if s.playable(c) {
+			playable = append(playable, c)
+		}
+	}
+	if len(playable) > 0 {
+		c := playable[s.rn.Intn(len(playable))]
+		s.discard(s.current, c)
+		s.playTile(c, -1)
+	}
+	s.endTurn()
+	return len(playable) > 0
+}
+
+func (s *simulation) endGameConditions() bool {
+	return rules.EndGameConditions(s.size[:], s.rules.EndGameCorporationSize, s.rules.SafeCorporationSize)
+}
+
+// playout plays random turns until the end of the game, or until
+// maxTurns have been played
+func (s *simulation) playout(maxTurns int) {
+	for i := 0; i < maxTurns && !s.endGameConditions(); i++ {
+		if !s.turn() && len(s.bag) == 0 {
+			return
+		}
+	}
+}
+
+// position returns the final position of the passed player if the game ended now,
+// tied players sharing the same one
+func (s *simulation) position(player int) int {
+	worth := make([]int, len(s.players))
+	for i, p := range s.players {
+		worth[i] = p.cash
+	}
+	for corp, size := range s.size {
+		if size == 0 {
+			continue
+		}
+		for i, amount := range s.bonuses(corp) {
+			worth[i] += amount + s.players[i].shares[corp]*s.price(corp)
+		}
+	}
+	position := 1
+	for i := range worth {
+		if worth[i] > worth[player] {
+			position++
+		}
+	}
+	return position
+}
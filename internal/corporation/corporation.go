@@ -1,20 +1,82 @@
 // Package corporation contains the model Corporation and attached methods which manages corporations in game
 package corporation
 
-import acquireCorporation "github.com/svera/acquire/corporation"
+import (
+	"errors"
+
+	acquireCorporation "github.com/svera/acquire/corporation"
+)
+
+// Tier defines the price range of a corporation stock
+type Tier int
+
+// These are the available tiers, from the cheapest to the most expensive one
+const (
+	Cheap Tier = iota
+	Medium
+	Expensive
+)
+
+// WrongTier is an error returned when parsing an unknown tier name
+const WrongTier = "wrong_tier"
+
+var tierNames = [3]string{"cheap", "medium", "expensive"}
+
+// String returns the tier name
+func (t Tier) String() string {
+	return tierNames[t]
+}
+
+// ParseTier returns the tier with the passed name
+func ParseTier(name string) (Tier, error) {
+	for i, tierName := range tierNames {
+		if tierName == name {
+			return Tier(i), nil
+		}
+	}
+	return Cheap, errors.New(WrongTier)
+}
+
+// StockPrice returns the price of a share of a corporation of the passed size and tier,
+// following the official price chart
+func StockPrice(size int, tier Tier) int {
+	var price int
+	switch {
+	case size < 2:
+		return 0
+	case size <= 5:
+		price = size * 100
+	case size <= 10:
+		price = 600
+	case size <= 20:
+		price = 700
+	case size <= 30:
+		price = 800
+	case size <= 40:
+		price = 900
+	default:
+		price = 1000
+	}
+	return price + int(tier)*100
+}
 
 // Corporation holds data related to corporations
 type Corporation struct {
 	*acquireCorporation.Corporation
 	name  string
+	id    string
+	tier  Tier
 	index int
 }
 
-// New initialises and returns a new instance of Corporation
-func New(name string, index int) *Corporation {
+// New initialises and returns a new instance of Corporation. The id identifies
+// the corporation in clients, which can use it to choose its color or logo.
+func New(name string, id string, tier Tier, index int) *Corporation {
 	return &Corporation{
 		acquireCorporation.New(),
 		name,
+		id,
+		tier,
 		index,
 	}
 }
@@ -24,7 +86,32 @@ func (c *Corporation) Name() string {
 	return c.name
 }
 
+// ID returns the corporation identifier
+func (c *Corporation) ID() string {
+	return c.id
+}
+
+// Tier returns the corporation price tier
+func (c *Corporation) Tier() Tier {
+	return c.tier
+}
+
 // Index returns the corporation position in the corporations array
 func (c *Corporation) Index() int {
 	return c.index
 }
+
+// StockPrice returns the price of a share of the corporation, depending on its size and tier
+func (c *Corporation) StockPrice() int {
+	return StockPrice(c.Size(), c.tier)
+}
+
+// MajorityBonus returns the bonus paid to the main shareholder of the corporation
+func (c *Corporation) MajorityBonus() int {
+	return c.StockPrice() * 10
+}
+
+// MinorityBonus returns the bonus paid to the second shareholder of the corporation
+func (c *Corporation) MinorityBonus() int {
+	return c.StockPrice() * 5
+}
@@ -0,0 +1,122 @@
+package messages
+
+import (
+	"errors"
+	"strconv"
+)
+
+// These are the encodings in which the board can be sent to clients
+const (
+	// BoardMap sends the board as a map of cells coordinates to their owners,
+	// as in the "brd" field of status messages
+	BoardMap = "map"
+	// BoardArray sends the board as a 9x12 array of cell codes in the "cbd" field
+	BoardArray = "array"
+	// BoardPacked sends the board as a string of 108 cell codes in the "pbd" field
+	BoardPacked = "packed"
+)
+
+// WrongBoard is an error returned when a board can not be encoded or decoded
+const WrongBoard = "wrong_board"
+
+const (
+	boardLetters = "ABCDEFGHI"
+	boardNumbers = 12
+)
+
+// These are the codes of the board cells in the compact encodings. Cells belonging
+// to a corporation are encoded as CellCorporation plus the corporation index.
+const (
+	CellEmpty          = 0
+	CellUnincorporated = 1
+	CellCorporation    = 2
+)
+
+// CompactBoard stores the board as an array of cell codes, with a row per letter
+// and a column per number, so cell "5C" is at [2][4].
+//
+//   [
+//     [0, 0, 1, 0, 2, 2, 0, 0, 0, 0, 0, 0], // Row A: 3A unincorporated, 5A and 6A belong to corporation 0
+//     [0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 8, 8], // Row B: 11B and 12B belong to corporation 6
+//     ...
+//   ]
+//
+// The packed encoding is the same array as a string, row by row, with every
+// code written as a single digit:
+//
+//   "001022000000000000000088..."
+type CompactBoard [9][12]int
+
+// EncodeBoard returns the compact encoding of a board map
+func EncodeBoard(board map[string]string) (CompactBoard, error) {
+	var compact CompactBoard
+
+	for row := range compact {
+		for column := range compact[row] {
+			owner := board[strconv.Itoa(column+1)+boardLetters[row:row+1]]
+			switch owner {
+			case "", "empty":
+				compact[row][column] = CellEmpty
+			case "unincorporated":
+				compact[row][column] = CellUnincorporated
+			default:
+				index, err := strconv.Atoi(owner)
+				if err != nil || index < 0 || index > 6 {
+					return compact, errors.New(WrongBoard)
+				}
+				compact[row][column] = CellCorporation + index
+			}
+		}
+	}
+	return compact, nil
+}
+
+// DecodeBoard returns the board map of a compact encoded board
+func DecodeBoard(compact CompactBoard) (map[string]string, error) {
+	board := map[string]string{}
+
+	for row := range compact {
+		for column, code := range compact[row] {
+			coords := strconv.Itoa(column+1) + boardLetters[row:row+1]
+			switch {
+			case code == CellEmpty:
+				board[coords] = "empty"
+			case code == CellUnincorporated:
+				board[coords] = "unincorporated"
+			case code >= CellCorporation && code <= CellCorporation+6:
+				board[coords] = strconv.Itoa(code - CellCorporation)
+			default:
+				return nil, errors.New(WrongBoard)
+			}
+		}
+	}
+	return board, nil
+}
+
+// Pack returns the packed encoding of the board
+func (c CompactBoard) Pack() string {
+	packed := make([]byte, 0, len(boardLetters)*boardNumbers)
+	for _, row := range c {
+		for _, code := range row {
+			packed = append(packed, byte('0'+code))
+		}
+	}
+	return string(packed)
+}
+
+// UnpackBoard returns the compact board stored in a packed string
+func UnpackBoard(packed string) (CompactBoard, error) {
+	var compact CompactBoard
+
+	if len(packed) != len(boardLetters)*boardNumbers {
+		return compact, errors.New(WrongBoard)
+	}
+	for i := range packed {
+		code := int(packed[i] - '0')
+		if packed[i] < '0' || code > CellCorporation+6 {
+			return compact, errors.New(WrongBoard)
+		}
+		compact[i/boardNumbers][i%boardNumbers] = code
+	}
+	return compact, nil
+}
@@ -0,0 +1,32 @@
+package messages
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBoardEncodingRoundTrip(t *testing.T) {
+	compact := CompactBoard{}
+	compact[0][2] = CellUnincorporated
+	compact[2][4] = CellCorporation + 6
+
+	board, err := DecodeBoard(compact)
+	if err != nil || board["5C"] != "6" || board["3A"] != "unincorporated" || board["1A"] != "empty" {
+		t.Errorf("Cells must be decoded to their owners, got %v", board)
+	}
+
+	encoded, err := EncodeBoard(board)
+	if err != nil || encoded != compact {
+		t.Errorf("Decoded board must be encoded back to the same array, got %v", encoded)
+	}
+	unpacked, err := UnpackBoard(compact.Pack())
+	if err != nil || !reflect.DeepEqual(unpacked, compact) {
+		t.Errorf("Packed board must be unpacked to the same array, got %v", unpacked)
+	}
+}
+
+func TestUnpackWrongBoard(t *testing.T) {
+	if _, err := UnpackBoard("0012"); err == nil {
+		t.Errorf("Unpacking a string with a wrong length must return an error")
+	}
+}
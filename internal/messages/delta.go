@@ -0,0 +1,117 @@
+package messages
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// StatusDelta stores the differences between two status messages.
+// Only changed values are included: board cells, corporations (indexed
+// by their position) and players (indexed by their names), along with the names
+// of the players who left, and tiles placed on the board, in order and by cell.
+// Clients which chose a compact board encoding get the whole board in it, in "cbd"
+// or "pbd", if any cell changed, instead of the changed cells in "brd". History,
+// legal actions, pending undo, last merge and final result are always the current
+// ones, being absent if there are none. The hand and the last round flag are included
+// whenever they change, even if the hand becomes empty or the flag false.
+//
+//   {
+//     "brd": {"5C": "0", "6C": "0"},
+//     "cor": {
+//       "0": {"nam": "Sackson", "prc": 300, ...}
+//     },
+//     "ply": {
+//       "John": {"nam": "John", "csh": 5400, ...}
+//     },
+//     "lft": ["Doe"],
+//     "sta": "BuyStock",
+//     "his": [...]
+//   }
+type StatusDelta struct {
+	Board       map[string]string     `json:"brd,omitempty"`
+	Compact     *CompactBoard         `json:"cbd,omitempty"`
+	Packed      string                `json:"pbd,omitempty"`
+	Corps       map[string]CorpData   `json:"cor,omitempty"`
+	Players     map[string]PlayerData `json:"ply,omitempty"`
+	Left        []string              `json:"lft,omitempty"`
+	Hand        *map[string]bool      `json:"hnd,omitempty"`
+	State       string                `json:"sta,omitempty"`
+	RoundNumber int                   `json:"rnd,omitempty"`
+	IsLastRound *bool                 `json:"lst,omitempty"`
+	History     []I18n                `json:"his,omitempty"`
+	Final       *FinalResult          `json:"fin,omitempty"`
+	Legal       *LegalActions         `json:"lgl,omitempty"`
+	Undo        *UndoData             `json:"und,omitempty"`
+	Merge       *MergeData            `json:"mrg,omitempty"`
+	Placements  []Placement           `json:"plc,omitempty"`
+	Cells       map[string]Placed     `json:"cel,omitempty"`
+}
+
+// Diff returns the changes needed to get the current status from the previous one
+func Diff(previous Status, current Status) StatusDelta {
+	delta := StatusDelta{
+		Board:   map[string]string{},
+		Corps:   map[string]CorpData{},
+		Players: map[string]PlayerData{},
+		History: current.History,
+		Final:   current.Final,
+		Legal:   current.Legal,
+		Undo:    current.Undo,
+		Merge:   current.Merge,
+	}
+
+	for coords, owner := range current.Board {
+		if previous.Board[coords] != owner {
+			delta.Board[coords] = owner
+		}
+	}
+	for i, corp := range current.Corps {
+		if previous.Corps[i] != corp {
+			delta.Corps[strconv.Itoa(i)] = corp
+		}
+	}
+
+	previousPlayers := map[string]PlayerData{}
+	for _, pl := range append([]PlayerData{previous.PlayerInfo}, previous.RivalsInfo...) {
+		previousPlayers[pl.Name] = pl
+	}
+	for _, pl := range append([]PlayerData{current.PlayerInfo}, current.RivalsInfo...) {
+		if pl.Name == "" {
+			continue
+		}
+		if prev, exists := previousPlayers[pl.Name]; !exists || !reflect.DeepEqual(prev, pl) {
+			delta.Players[pl.Name] = pl
+		}
+		delete(previousPlayers, pl.Name)
+	}
+	for name := range previousPlayers {
+		if name != "" {
+			delta.Left = append(delta.Left, name)
+		}
+	}
+	sort.Strings(delta.Left)
+
+	if !reflect.DeepEqual(previous.Hand, current.Hand) {
+		hand := current.Hand
+		delta.Hand = &hand
+	}
+	if len(current.Placements) > len(previous.Placements) {
+		delta.Placements = current.Placements[len(previous.Placements):]
+		delta.Cells = map[string]Placed{}
+		for _, placement := range delta.Placements {
+			delta.Cells[placement.Tile] = current.Cells[placement.Tile]
+		}
+	}
+	if previous.State != current.State {
+		delta.State = current.State
+	}
+	if previous.RoundNumber != current.RoundNumber {
+		delta.RoundNumber = current.RoundNumber
+	}
+	if previous.IsLastRound != current.IsLastRound {
+		lastRound := current.IsLastRound
+		delta.IsLastRound = &lastRound
+	}
+	return delta
+}
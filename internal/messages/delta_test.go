@@ -0,0 +1,21 @@
+package messages
+
+import (
+	"testing"
+)
+
+func TestDiffIncludesValuesChangedToEmpty(t *testing.T) {
+	previous := Status{Hand: map[string]bool{"1A": true}, IsLastRound: true}
+	current := Status{Hand: map[string]bool{}, IsLastRound: false}
+
+	delta := Diff(previous, current)
+	if delta.Hand == nil || len(*delta.Hand) != 0 {
+		t.Errorf("Delta must include a hand which became empty, got %v", delta.Hand)
+	}
+	if delta.IsLastRound == nil || *delta.IsLastRound {
+		t.Errorf("Delta must include a last round flag which became false, got %v", delta.IsLastRound)
+	}
+	if delta := Diff(current, current); delta.Hand != nil || delta.IsLastRound != nil {
+		t.Errorf("Delta must not include the hand or the last round flag if they did not change")
+	}
+}
@@ -10,6 +10,8 @@ const (
 	TypeUntieMerge       = "unt"
 	TypeEndGame          = "end"
 	TypeClientOut        = "out"
+	TypeUndo             = "und"
+	TypeUndoVote         = "vot"
 )
 
 // PlayTile is a struct which defines the content of
@@ -108,6 +110,23 @@ type UntieMerge struct {
 	CorporationIndex int `json:"cor"`
 }
 
+// UndoVote is a struct which defines the content of
+// incoming undo vote messages, sent by players to approve or
+// reject an undo requested by another player. Undo requests
+// ("typ": "und") have no content.
+//
+// The following is an undo vote message example:
+//
+//   {
+//     "typ": "vot",
+//     "cnt": {
+//       "acc": true
+//     }
+//   }
+type UndoVote struct {
+	Accept bool `json:"acc"`
+}
+
 type ClientOut struct {
 	Reason string `json:"rea"`
 }
@@ -6,72 +6,171 @@ package messages
 // Status is a struct which contains the status of the game at the moment
 // it is issued. It is sent to each player after every action made by one of them.
 //
-//   {
-//      "typ": "upd", // Type: update
-//      "cnt": {
-//        "brd": { // Board state
-//          "1A": "empty", // Board cell 1A is empty
-//          "1B": "unincorporated", // Board cell 1B is unincorporated
-//          "1C": "empty",
-//          "1D": "0", // Board cell 1A belongs to corporation 0
-//          ...
-//        }
-//        "sta": "PlayTile",
-//        "hnd": {
-//          "1A": true, // Player has tile 1A and it is playable
-//        },
-//        "cor": [
-//          {
-//            "nam": "Hilton",
-//            "prc": 100, // Corporation stock price
-//            "maj": 400, // Corporation majority bonus
-//            "min": 200, // Corporation minority bonus
-//            "rem": 20,  // Remaining stock shares
-//            "siz": 2,   // Corporation size
-//            "def": false, // Is corporation defunct? (in corporation merges)
-//            "tie": false, // Is corporation part of a tied merge?
-//          },
-//          ...
-//        ],
-//        "ply": {
-//          "nam": "John",
-//          "trn": true, // Is player currently in turn?
-//          "csh": 6000, // Player cash
-//          "own": [     // Player owned shares per corporation
-//            0: 2,
-//            1: 0,
-//            ...
-//          ]
-//        },
-//        "riv": [
-//          {
-//            "nam": "Doe",
-//            "trn": false,
-//            "csh": 6000,
-//            "own": [
-//              0: 2,
-//              1: 0,
-//              ...
-//            ]
-//          },
-//          ...
-//        ],
-//        "rnd": 3, // Round number
-//        "lst": false, // Is last round?
-//        "his": [ // History log (i18n enabled)
-//          {
-//            "key": "translation_key",
-//            "arg": {
-//              "argument_name": "argument_value",
-//              ...
-//            }
-//          },
-//          ...
-//        ]
-//      }
-//   }
+// Spectators receive the same message with an empty "hnd" and "ply", and
+// all players listed in "riv". Referees (omniscient spectators) also receive
+// the hand of every player inside its "riv" entry.
+//
+//	{
+//	   "typ": "upd", // Type: update
+//	   "cnt": {
+//	     "brd": { // Board state
+//	       "1A": "empty", // Board cell 1A is empty
+//	       "1B": "unincorporated", // Board cell 1B is unincorporated
+//	       "1C": "empty",
+//	       "1D": "0", // Board cell 1A belongs to corporation 0
+//	       ...
+//	     }
+//	     "cbd": [[0, 1, ...], ...], // Board as an array of cell codes, sent instead of "brd",
+//	                                // which is null then, to clients which choose it (see CompactBoard)
+//	     "pbd": "0100...",          // Board as a packed string, sent instead of "brd",
+//	                                // which is null then, to clients which choose it (see CompactBoard)
+//	     "sta": "PlayTile",
+//	     "hnd": {
+//	       "1A": true, // Player has tile 1A and it is playable
+//	     },
+//	     "cor": [
+//	       {
+//	         "nam": "Hilton",
+//	         "id": "hilton", // Corporation identifier, for clients to choose its color or logo
+//	         "tir": "cheap", // Price tier: "cheap", "medium" or "expensive"
+//	         "prc": 100, // Corporation stock price
+//	         "maj": 400, // Corporation majority bonus
+//	         "min": 200, // Corporation minority bonus
+//	         "rem": 20,  // Remaining stock shares
+//	         "siz": 2,   // Corporation size
+//	         "def": false, // Is corporation defunct? (in corporation merges)
+//	         "tie": false, // Is corporation part of a tied merge?
+//	       },
+//	       ...
+//	     ],
+//	     "ply": {
+//	       "nam": "John",
+//	       "trn": true, // Is player currently in turn?
+//	       "csh": 6000, // Player cash
+//	       "own": [     // Player owned shares per corporation
+//	         0: 2,
+//	         1: 0,
+//	         ...
+//	       ]
+//	     },
+//	     "riv": [
+//	       {
+//	         "nam": "Doe",
+//	         "trn": false,
+//	         "csh": 6000,
+//	         "own": [
+//	           0: 2,
+//	           1: 0,
+//	           ...
+//	         ],
+//	         "hcs": false, // Is cash hidden? (only present if true)
+//	         "hsh": false, // Are owned shares hidden? (only present if true)
+//	         "clk": {      // Remaining time, only present if the game has time limits
+//	           "act": 30000, // Milliseconds left for the current decision
+//	           "bnk": 600000 // Milliseconds left in the time bank
+//	         },
+//	         "bot": "greedy", // Level of the bot playing for a player who left (only present if so)
+//	         "ntr": true,     // Is it the neutral Stock Market of the house two players variant? (only present if so)
+//	         "tea": "red"     // Team of the player, only present in team games. Teammates
+//	                          // are never masked and their hands are included in "hnd"
+//	       },
+//	       ...
+//	     ],
+//	     "ver": 42, // Status version, increased every time the game changes
+//	     "rul": { // House rules of the game
+//	       "buy": 3,  // Maximum amount of shares which can be bought in a turn
+//	       "end": 41, // Size a corporation must reach to claim the end of the game
+//	       "saf": 11, // Size from which a corporation is safe
+//	       "ncp": 7   // Number of corporations which can be founded
+//	     },
+//	     "plc": [ // Tiles placed on the board, in order, only present if the game options say so
+//	       {
+//	         "til": "5C",
+//	         "nam": "John", // Player who placed it
+//	         "rnd": 1       // Round in which it was placed
+//	       },
+//	       ...
+//	     ],
+//	     "cel": { // Who placed every tile on the board and when, by cell, only present with "plc"
+//	       "5C": {"nam": "John", "rnd": 1},
+//	       ...
+//	     },
+//	     "rnd": 3, // Round number
+//	     "lst": false, // Is last round?
+//	     "his": [ // History log (i18n enabled)
+//	       {
+//	         "key": "translation_key",
+//	         "arg": {
+//	           "argument_name": "argument_value",
+//	           ...
+//	         }
+//	       },
+//	       ...
+//	     ],
+//	     "lgl": { // Legal actions, only present for the player in turn
+//	       "til": ["2A", "5C"], // Playable tiles
+//	       "cor": [3, 4],       // Corporations which can be founded
+//	       "buy": [{}, {"0": 1}, {"0": 2}, {"0": 1, "2": 1}, ...], // Stock purchases
+//	       "sel": {             // Sell and trade combinations per defunct corporation
+//	         "1": [{"sel": 0, "tra": 0}, {"sel": 1, "tra": 0}, {"sel": 0, "tra": 2}, ...]
+//	       },
+//	       "unt": [1, 5],       // Corporations which can be chosen to untie a merge
+//	       "end": false         // Can end game be claimed?
+//	     },
+//	     "und": { // Pending undo request, only present while waiting for approvals
+//	       "nam": "John",        // Player who requested it
+//	       "apr": ["John", "Doe"] // Players who approved it
+//	     },
+//	     "mrg": { // Last merge, present until its shareholders have sold, traded or kept their shares
+//	       "acq": 2,              // Acquirer corporation index
+//	       "def": [               // Defunct corporations, in the order they are processed
+//	         {
+//	           "idx": 0,
+//	           "nam": "Sackson",
+//	           "siz": 5,          // Size before the merge
+//	           "prc": 500
+//	         }
+//	       ],
+//	       "bon": [{"nam": "John", "amt": 5000}, {"nam": "Doe", "amt": 2500}] // Bonuses paid to every player
+//	     },
+//	     "fin": { // Final result, only present when the game has reached its end
+//	       "ply": [
+//	         {
+//	           "nam": "John",
+//	           "csh": 6000,  // Cash before end game liquidation
+//	           "bon": 3000,  // Bonuses received at end game, as paid by the engine
+//	           "liq": 2400,  // Money received from selling all owned shares
+//	           "tot": 11400, // Final cash
+//	         },
+//	         ...
+//	       ],
+//	       "cor": [
+//	         {
+//	           "idx": 0,
+//	           "nam": "Sackson",
+//	           "prc": 600,
+//	           "maj": 6000, // Majority bonus, shared by the engine among the main shareholders
+//	           "min": 3000  // Minority bonus
+//	         },
+//	         ...
+//	       ],
+//	       "rnk": [
+//	         {"pos": 1, "nam": "John", "tot": 11400},
+//	         {"pos": 2, "nam": "Doe", "tot": 9800},
+//	         {"pos": 2, "nam": "Jane", "tot": 9800}, // Tied players share position
+//	         ...
+//	       ],
+//	       "trk": [ // Only in team games, "nam" being the team and "tot" the sum of its players
+//	         {"pos": 1, "nam": "red", "tot": 21200},
+//	         ...
+//	       ]
+//	     }
+//	   }
+//	}
 type Status struct {
 	Board       map[string]string `json:"brd"`
+	Compact     *CompactBoard     `json:"cbd,omitempty"`
+	Packed      string            `json:"pbd,omitempty"`
 	State       string            `json:"sta"`
 	Hand        map[string]bool   `json:"hnd"`
 	Corps       [7]CorpData       `json:"cor"`
@@ -80,11 +179,21 @@ type Status struct {
 	RoundNumber int               `json:"rnd"`
 	IsLastRound bool              `json:"lst"`
 	History     []I18n            `json:"his"`
+	Final       *FinalResult      `json:"fin,omitempty"`
+	Legal       *LegalActions     `json:"lgl,omitempty"`
+	Undo        *UndoData         `json:"und,omitempty"`
+	Merge       *MergeData        `json:"mrg,omitempty"`
+	Version     int               `json:"ver"`
+	Placements  []Placement       `json:"plc,omitempty"`
+	Cells       map[string]Placed `json:"cel,omitempty"`
+	Rules       *Rules            `json:"rul,omitempty"`
 }
 
 // CorpData stores all corporation information
 type CorpData struct {
 	Name            string `json:"nam"`
+	ID              string `json:"id"`
+	Tier            string `json:"tir"`
 	Price           int    `json:"prc"`
 	MajorityBonus   int    `json:"maj"`
 	MinorityBonus   int    `json:"min"`
@@ -94,12 +203,21 @@ type CorpData struct {
 	Tied            bool   `json:"tie"`
 }
 
-// PlayerData stores all player information
+// PlayerData stores all player information. Hand is only filled
+// in the omniscient spectator status and for teammates. When the game hides cash or shares
+// from rivals, their values are zeroed and the matching flag is set.
 type PlayerData struct {
-	Name        string `json:"nam"`
-	InTurn      bool   `json:"trn"`
-	Cash        int    `json:"csh"`
-	OwnedShares [7]int `json:"own"`
+	Name         string          `json:"nam"`
+	InTurn       bool            `json:"trn"`
+	Cash         int             `json:"csh"`
+	OwnedShares  [7]int          `json:"own"`
+	Hand         map[string]bool `json:"hnd,omitempty"`
+	CashHidden   bool            `json:"hcs,omitempty"`
+	SharesHidden bool            `json:"hsh,omitempty"`
+	Clock        *ClockData      `json:"clk,omitempty"`
+	Bot          string          `json:"bot,omitempty"`
+	Neutral      bool            `json:"ntr,omitempty"`
+	Team         string          `json:"tea,omitempty"`
 }
 
 // I18n stores strings to be translated by the frontend, as well as related variables.
@@ -107,3 +225,196 @@ type I18n struct {
 	Key       string            `json:"key"`
 	Arguments map[string]string `json:"arg"`
 }
+
+// FinalResult stores the final standings of a finished game
+type FinalResult struct {
+	Players      []FinalPlayerData `json:"ply"`
+	Corporations []FinalCorpData   `json:"cor"`
+	Ranking      []RankData        `json:"rnk"`
+	TeamRanking  []RankData        `json:"trk,omitempty"`
+}
+
+// FinalPlayerData stores how the final cash of a player is obtained
+type FinalPlayerData struct {
+	Name        string `json:"nam"`
+	Cash        int    `json:"csh"`
+	Bonuses     int    `json:"bon"`
+	Liquidation int    `json:"liq"`
+	Total       int    `json:"tot"`
+}
+
+// FinalCorpData stores the bonuses paid by an active corporation at game end
+type FinalCorpData struct {
+	Index    int    `json:"idx"`
+	Name     string `json:"nam"`
+	Price    int    `json:"prc"`
+	Majority int    `json:"maj"`
+	Minority int    `json:"min"`
+}
+
+// BonusData stores a bonus paid to a shareholder
+type BonusData struct {
+	Name   string `json:"nam"`
+	Amount int    `json:"amt"`
+}
+
+// RankData stores the position of a player in the final ranking
+type RankData struct {
+	Position int    `json:"pos"`
+	Name     string `json:"nam"`
+	Total    int    `json:"tot"`
+}
+
+// LegalActions lists the actions a player can take in the current game state
+type LegalActions struct {
+	Tiles        []string                      `json:"til,omitempty"`
+	Corporations []int                         `json:"cor,omitempty"`
+	Buy          []map[string]int              `json:"buy,omitempty"`
+	SellTrade    map[string][]SellTradeAmounts `json:"sel,omitempty"`
+	Untie        []int                         `json:"unt,omitempty"`
+	ClaimEndGame bool                          `json:"end"`
+}
+
+// Update is sent to a client which already has a previous status, with the
+// changes since then. If the client status is not known, the full one is sent instead.
+//
+//	{
+//	  "ver": 43, // Current status version
+//	  "bas": 42, // Version the changes apply to, only present along with "dlt"
+//	  "dlt": {...}, // Changes since the base version
+//	  "ful": {...}  // Full status, only present if no delta can be sent
+//	}
+type Update struct {
+	Version int          `json:"ver"`
+	Base    int          `json:"bas,omitempty"`
+	Delta   *StatusDelta `json:"dlt,omitempty"`
+	Full    *Status      `json:"ful,omitempty"`
+}
+
+// Preview stores what would happen if an action were executed. If the action
+// is not valid, the reason why is stored in Error.
+//
+//	{
+//	  "val": true,
+//	  "sta": "SellTrade", // Resulting game state
+//	  "his": [...],       // History entries the action would generate
+//	  "dlt": {...}        // Changes in the status of the acting player
+//	}
+type Preview struct {
+	Valid   bool        `json:"val"`
+	Error   *I18n       `json:"err,omitempty"`
+	State   string      `json:"sta,omitempty"`
+	History []I18n      `json:"his,omitempty"`
+	Delta   StatusDelta `json:"dlt"`
+}
+
+// UndoData stores an undo request waiting for the approval of all players
+type UndoData struct {
+	Name      string   `json:"nam"`
+	Approvals []string `json:"apr"`
+}
+
+// MergeData stores the breakdown of the last merge, with the defunct corporations
+// listed in the order they are processed, and the bonuses paid to every player
+type MergeData struct {
+	Acquirer int           `json:"acq"`
+	Defunct  []DefunctData `json:"def"`
+	Bonuses  []BonusData   `json:"bon"`
+}
+
+// DefunctData stores the size and price of a corporation absorbed in a merge
+type DefunctData struct {
+	Index int    `json:"idx"`
+	Name  string `json:"nam"`
+	Size  int    `json:"siz"`
+	Price int    `json:"prc"`
+}
+
+// ClockData stores the time a player has left, in milliseconds
+type ClockData struct {
+	Action int64 `json:"act"`
+	Bank   int64 `json:"bnk"`
+}
+
+// Placement stores who placed a tile on the board and when
+type Placement struct {
+	Tile  string `json:"til"`
+	Name  string `json:"nam"`
+	Round int    `json:"rnd"`
+}
+
+// Placed stores who placed the tile in a board cell and when
+type Placed struct {
+	Name  string `json:"nam"`
+	Round int    `json:"rnd"`
+}
+
+// Statistics stores what every player did during a game and how long every corporation lasted
+//
+//	{
+//	  "ply": [
+//	    {
+//	      "nam": "John",
+//	      "buy": [3, 0, 5, 0, 0, 2, 0], // Shares bought per corporation
+//	      "spt": 4500, // Money spent buying shares
+//	      "bon": 7000, // Bonuses received, including the end game ones
+//	      "fnd": 2,    // Corporations founded
+//	      "mrg": 1,    // Merges triggered
+//	      "til": 12,   // Tiles played
+//	      "dis": 1     // Dead tiles discarded
+//	    },
+//	    ...
+//	  ],
+//	  "cor": [
+//	    {
+//	      "idx": 0,
+//	      "nam": "Sackson",
+//	      "lif": [ // Every time the corporation was active
+//	        {
+//	          "fnd": 2,  // Round in which it was founded
+//	          "pk": 14,  // Peak size
+//	          "def": 7   // Round in which it became defunct, only present if so
+//	        }
+//	      ]
+//	    },
+//	    ...
+//	  ]
+//	}
+type Statistics struct {
+	Players      []PlayerStatistics      `json:"ply"`
+	Corporations []CorporationStatistics `json:"cor"`
+}
+
+// PlayerStatistics stores what a player did during a game
+type PlayerStatistics struct {
+	Name         string `json:"nam"`
+	SharesBought [7]int `json:"buy"`
+	Spent        int    `json:"spt"`
+	Bonuses      int    `json:"bon"`
+	Founded      int    `json:"fnd"`
+	Merges       int    `json:"mrg"`
+	TilesPlayed  int    `json:"til"`
+	Discarded    int    `json:"dis"`
+}
+
+// CorporationStatistics stores every period of time a corporation was active
+type CorporationStatistics struct {
+	Index     int        `json:"idx"`
+	Name      string     `json:"nam"`
+	Lifetimes []Lifetime `json:"lif"`
+}
+
+// Lifetime stores a period of time a corporation was active
+type Lifetime struct {
+	Founded  int `json:"fnd"`
+	PeakSize int `json:"pk"`
+	Defunct  int `json:"def,omitempty"`
+}
+
+// Rules stores the house rules a game is played with
+type Rules struct {
+	MaxBuy                 int `json:"buy"`
+	EndGameCorporationSize int `json:"end"`
+	SafeCorporationSize    int `json:"saf"`
+	Corporations           int `json:"ncp"`
+}
@@ -0,0 +1,205 @@
+// Package replay records every action accepted by the driver, so a game can be
+// reviewed and rebuilt up to any of its points by executing them again
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/sackson-server/api"
+)
+
+// OutOfRange is an error returned when trying to rebuild a game beyond the recorded actions
+const OutOfRange = "replay_out_of_range"
+
+// These are the types of the entries which record a change of seat instead of an action
+const (
+	// TypeSubstituted records that a bot took the seat of a player
+	TypeSubstituted = "sub"
+	// TypeReclaimed records that a player took back its seat from a bot
+	TypeReclaimed = "rcl"
+)
+
+// Substitution stores the params of a TypeSubstituted entry
+type Substitution struct {
+	Level string `json:"lvl"`
+}
+
+// Entry stores an action accepted by the driver and the game state it led to
+type Entry struct {
+	PlayerNumber int             `json:"num"`
+	PlayerName   string          `json:"nam"`
+	Type         string          `json:"typ"`
+	Params       json.RawMessage `json:"cnt"`
+	State        string          `json:"sta"`
+	Time         time.Time       `json:"tim"`
+	History      []messages.I18n `json:"his"`
+}
+
+// Log holds everything needed to rebuild a game: the way it was started
+// and the actions executed since then, in order
+type Log struct {
+	Seed    int64            `json:"sed"`
+	Options json.RawMessage  `json:"opt"`
+	Players map[int]string   `json:"ply"`
+	Tiles   []string         `json:"til"`
+	Hands   map[int][]string `json:"hnd"`
+	Starter int              `json:"stp"`
+	Entries []Entry          `json:"ent"`
+	// Seeds the tiles not drawn yet were shuffled with when undoing actions,
+	// by the number of entries executed before
+	Shuffles map[int][]int64 `json:"shf,omitempty"`
+}
+
+// Game is implemented by the drivers which can be rebuilt from a log
+type Game interface {
+	Execute(action api.Action) error
+	RemovePlayer(number int) error
+	SubstitutePlayer(number int, level string) error
+	ReclaimPlayer(number int) error
+	ShuffleTiles(seed int64)
+}
+
+// New initialises and returns a new instance of Log
+func New(seed int64, options json.RawMessage, players map[int]string, tiles []string) *Log {
+	return &Log{
+		Seed:    seed,
+		Options: options,
+		Players: players,
+		Tiles:   tiles,
+		Hands:   map[int][]string{},
+		Entries: []Entry{},
+	}
+}
+
+// Record appends an entry to the log
+func (l *Log) Record(entry Entry) {
+	l.Entries = append(l.Entries, entry)
+}
+
+// Action returns the action stored in the passed entry, as it was received by the driver
+func (l *Log) Action(n int) api.Action {
+	return api.Action{
+		PlayerName: l.Entries[n].PlayerName,
+		Type:       l.Entries[n].Type,
+		Params:     l.Entries[n].Params,
+	}
+}
+
+// Rebuild executes again the first n recorded actions on the game returned by start,
+// which must be started the same way the recorded one was
+func Rebuild(l *Log, n int, start func() (Game, error)) (Game, error) {
+	if n < 0 || n > len(l.Entries) {
+		return nil, errors.New(OutOfRange)
+	}
+
+	game, err := start()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		l.shuffle(game, i)
+		if err = l.Apply(game, i); err != nil {
+			return nil, err
+		}
+	}
+	l.shuffle(game, n)
+	return game, nil
+}
+
+// shuffle shuffles the tiles of the game as they were after executing the first n entries
+func (l *Log) shuffle(game Game, n int) {
+	for _, seed := range l.Shuffles[n] {
+		game.ShuffleTiles(seed)
+	}
+}
+
+// Shuffle records that the tiles not drawn yet were shuffled with the passed seed
+// after executing all the entries in the log
+func (l *Log) Shuffle(seed int64) {
+	if l.Shuffles == nil {
+		l.Shuffles = map[int][]int64{}
+	}
+	l.Shuffles[len(l.Entries)] = append(l.Shuffles[len(l.Entries)], seed)
+}
+
+// Until returns a copy of the log with only its first n entries
+func (l *Log) Until(n int) *Log {
+	log := *l
+	log.Entries = append([]Entry{}, l.Entries[:n]...)
+	log.Shuffles = nil
+	for i, seeds := range l.Shuffles {
+		if i <= n {
+			log.shuffleAt(i, seeds)
+		}
+	}
+	return &log
+}
+
+// Without returns a copy of the log without the entry n
+func (l *Log) Without(n int) *Log {
+	log := *l
+	log.Entries = append(append([]Entry{}, l.Entries[:n]...), l.Entries[n+1:]...)
+	log.Shuffles = nil
+	for i, seeds := range l.Shuffles {
+		if i > n {
+			i--
+		}
+		log.shuffleAt(i, seeds)
+	}
+	return &log
+}
+
+func (l *Log) shuffleAt(n int, seeds []int64) {
+	if l.Shuffles == nil {
+		l.Shuffles = map[int][]int64{}
+	}
+	l.Shuffles[n] = append(l.Shuffles[n], seeds...)
+}
+
+// Apply executes again on the passed game the action stored in the entry n
+func (l *Log) Apply(game Game, n int) error {
+	entry := l.Entries[n]
+	switch entry.Type {
+	case messages.TypeClientOut:
+		return game.RemovePlayer(entry.PlayerNumber)
+	case TypeSubstituted:
+		var sub Substitution
+		if err := json.Unmarshal(entry.Params, &sub); err != nil {
+			return err
+		}
+		return game.SubstitutePlayer(entry.PlayerNumber, sub.Level)
+	case TypeReclaimed:
+		return game.ReclaimPlayer(entry.PlayerNumber)
+	}
+	return game.Execute(l.Action(n))
+}
+
+// IsAction returns true if the entry stores an action taken by a player
+// and not a change of seat
+func (e Entry) IsAction() bool {
+	switch e.Type {
+	case messages.TypeClientOut, TypeSubstituted, TypeReclaimed:
+		return false
+	}
+	return true
+}
+
+// Follows returns true if the passed log stores the same actions than the first ones
+// in l, no matter the time they were executed at
+func (l *Log) Follows(other *Log) bool {
+	if len(other.Entries) > len(l.Entries) {
+		return false
+	}
+	for i, entry := range other.Entries {
+		if entry.PlayerNumber != l.Entries[i].PlayerNumber ||
+			entry.Type != l.Entries[i].Type ||
+			!bytes.Equal(entry.Params, l.Entries[i].Params) {
+			return false
+		}
+	}
+	return true
+}
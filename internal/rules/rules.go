@@ -0,0 +1,172 @@
+// Package rules contains the Acquire rules needed both by the driver and by the bots,
+// so the two of them always apply the same ones
+package rules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Bonus stores the money a shareholder receives from a corporation. The holder is
+// identified by its position in the shares passed to Bonuses.
+type Bonus struct {
+	Holder int
+	Amount int
+}
+
+// Bonuses returns who receives the majority and minority bonuses of a corporation,
+// given the shares every holder owns, following the official rules: tied majority
+// shareholders split both bonuses, tied minority shareholders split the minority one
+// and a sole shareholder receives both of them
+func Bonuses(shares []int, majorityBonus int, minorityBonus int) ([]Bonus, []Bonus) {
+	var majorityHolders, minorityHolders []int
+	var majority, minority []Bonus
+	first, second := 0, 0
+
+	for _, amount := range shares {
+		if amount > first {
+			second = first
+			first = amount
+		} else if amount > second && amount < first {
+			second = amount
+		}
+	}
+	if first == 0 {
+		return majority, minority
+	}
+
+	for i, amount := range shares {
+		if amount == first {
+			majorityHolders = append(majorityHolders, i)
+		} else if amount == second && second > 0 {
+			minorityHolders = append(minorityHolders, i)
+		}
+	}
+
+	if len(majorityHolders) > 1 {
+		for _, i := range majorityHolders {
+			majority = append(majority, Bonus{i, SplitBonus(majorityBonus+minorityBonus, len(majorityHolders))})
+		}
+		return majority, minority
+	}
+
+	majority = append(majority, Bonus{majorityHolders[0], majorityBonus})
+	if len(minorityHolders) == 0 {
+		minority = append(minority, Bonus{majorityHolders[0], minorityBonus})
+		return majority, minority
+	}
+	for _, i := range minorityHolders {
+		minority = append(minority, Bonus{i, SplitBonus(minorityBonus, len(minorityHolders))})
+	}
+	return majority, minority
+}
+
+// SplitBonus divides a bonus between several shareholders, rounding each part
+// up to the nearest hundred
+func SplitBonus(amount int, holders int) int {
+	return (amount + holders*100 - 1) / (holders * 100) * 100
+}
+
+// Letters are the board rows, in order
+const Letters = "ABCDEFGHI"
+
+// Numbers is the amount of board columns
+const Numbers = 12
+
+// Cell is a position on the board, its number going from 1 to 12 and its letter
+// being the index of the row in Letters
+type Cell struct {
+	Number int
+	Letter int
+}
+
+// ParseCell returns the cell at the passed coordinates, as in "5C"
+func ParseCell(coords string) Cell {
+	number, _ := strconv.Atoi(coords[:len(coords)-1])
+	return Cell{Number: number, Letter: strings.Index(Letters, coords[len(coords)-1:])}
+}
+
+// String returns the coordinates of the cell, as in "5C"
+func (c Cell) String() string {
+	return strconv.Itoa(c.Number) + Letters[c.Letter:c.Letter+1]
+}
+
+// Adjacent returns the cells orthogonally adjacent to the passed one
+func (c Cell) Adjacent() []Cell {
+	cells := []Cell{}
+	if c.Number > 1 {
+		cells = append(cells, Cell{c.Number - 1, c.Letter})
+	}
+	if c.Number < Numbers {
+		cells = append(cells, Cell{c.Number + 1, c.Letter})
+	}
+	if c.Letter > 0 {
+		cells = append(cells, Cell{c.Number, c.Letter - 1})
+	}
+	if c.Letter < len(Letters)-1 {
+		cells = append(cells, Cell{c.Number, c.Letter + 1})
+	}
+	return cells
+}
+
+// Corporation stores what the rules need to know of a corporation
+type Corporation struct {
+	Size  int
+	Stock int
+	Price int
+}
+
+// StockCombinations returns every affordable combination of up to amount shares of
+// active corporations with stock left, each one being the indexes of the corporations
+// of the shares bought
+func StockCombinations(corps []Corporation, amount int, cash int) [][]int {
+	return stockCombinations(corps, 0, amount, cash)
+}
+
+func stockCombinations(corps []Corporation, from int, amount int, cash int) [][]int {
+	combinations := [][]int{{}}
+	if amount == 0 {
+		return combinations
+	}
+	for i := from; i < len(corps); i++ {
+		corp := corps[i]
+		if corp.Size == 0 || corp.Stock == 0 || corp.Price > cash {
+			continue
+		}
+		for _, rest := range stockCombinations(corps, i, amount-1, cash-corp.Price) {
+			if corp.Stock < occurrences(rest, i)+1 {
+				continue
+			}
+			combinations = append(combinations, append([]int{i}, rest...))
+		}
+	}
+	return combinations
+}
+
+func occurrences(values []int, value int) int {
+	n := 0
+	for _, v := range values {
+		if v == value {
+			n++
+		}
+	}
+	return n
+}
+
+// EndGameConditions returns true if a corporation of the passed sizes has reached
+// the end game size or all active corporations are safe
+func EndGameConditions(sizes []int, endGameSize int, safeSize int) bool {
+	var active, safe int
+	for _, size := range sizes {
+		if size >= endGameSize {
+			return true
+		}
+		if size > 0 {
+			active++
+		}
+		if size >= safeSize {
+			safe++
+		}
+	}
+	return active > 0 && active == safe
+}
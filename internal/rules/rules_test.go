@@ -0,0 +1,44 @@
+package rules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitBonus(t *testing.T) {
+	if amount := SplitBonus(3000, 2); amount != 1500 {
+		t.Errorf("Bonus split between 2 shareholders must be 1500, got %d", amount)
+	}
+	if amount := SplitBonus(5000, 3); amount != 1700 {
+		t.Errorf("Bonus split between 3 shareholders must be rounded up to 1700, got %d", amount)
+	}
+}
+
+func TestBonuses(t *testing.T) {
+	majority, minority := Bonuses([]int{4, 2, 0, 2}, 3000, 1500)
+	if !reflect.DeepEqual(majority, []Bonus{{0, 3000}}) || !reflect.DeepEqual(minority, []Bonus{{1, 800}, {3, 800}}) {
+		t.Errorf("Tied minority shareholders must split the minority bonus, got %v and %v", majority, minority)
+	}
+	majority, minority = Bonuses([]int{0, 3, 0}, 3000, 1500)
+	if !reflect.DeepEqual(majority, []Bonus{{1, 3000}}) || !reflect.DeepEqual(minority, []Bonus{{1, 1500}}) {
+		t.Errorf("A sole shareholder must receive both bonuses, got %v and %v", majority, minority)
+	}
+}
+
+func TestStockCombinationsRespectStockAndCash(t *testing.T) {
+	corps := []Corporation{{Size: 3, Stock: 2, Price: 300}, {Size: 6, Stock: 20, Price: 800}, {}}
+
+	// {}, {0}, {0, 0}, {1}
+	if combinations := StockCombinations(corps, 3, 1000); len(combinations) != 4 {
+		t.Errorf("Expected 4 stock combinations, got %v", combinations)
+	}
+}
+
+func TestAdjacentCells(t *testing.T) {
+	if cells := ParseCell("1A").Adjacent(); len(cells) != 2 || cells[0].String() != "2A" || cells[1].String() != "1B" {
+		t.Errorf("Corner cell must have 2 adjacent cells, got %v", cells)
+	}
+	if cells := ParseCell("12E").Adjacent(); len(cells) != 3 {
+		t.Errorf("Border cell must have 3 adjacent cells, got %v", cells)
+	}
+}
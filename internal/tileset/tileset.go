@@ -0,0 +1,66 @@
+// Package tileset contains the model TileSet, which stores the tiles yet to be drawn in a game
+package tileset
+
+import (
+	"errors"
+	"math/rand"
+
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
+)
+
+// NoTilesAvailable is an error returned when trying to draw a tile from an empty tile set
+const NoTilesAvailable = "no_tiles_available"
+
+// TileSet holds the tiles not drawn yet, in the order they will be drawn
+type TileSet struct {
+	tiles []acquireInterfaces.Tile
+}
+
+// New initialises and returns a new instance of TileSet, shuffled using the passed seed,
+// so the same seed always produces the same drawing order
+func New(seed int64) *TileSet {
+	var letters = [9]string{"A", "B", "C", "D", "E", "F", "G", "H", "I"}
+	ordered := make([]acquireInterfaces.Tile, 0, 108)
+	for number := 1; number < 13; number++ {
+		for _, letter := range letters {
+			ordered = append(ordered, tile.New(number, letter))
+		}
+	}
+
+	rn := rand.New(rand.NewSource(seed))
+	tiles := make([]acquireInterfaces.Tile, len(ordered))
+	for i, j := range rn.Perm(len(ordered)) {
+		tiles[i] = ordered[j]
+	}
+	return &TileSet{tiles: tiles}
+}
+
+// Draw extracts the next tile from the tile set and returns it
+func (t *TileSet) Draw() (acquireInterfaces.Tile, error) {
+	if len(t.tiles) == 0 {
+		return nil, errors.New(NoTilesAvailable)
+	}
+	tl := t.tiles[0]
+	t.tiles = t.tiles[1:]
+	return tl, nil
+}
+
+// Return puts back a tile in the tile set, to be drawn after the rest
+func (t *TileSet) Return(tl acquireInterfaces.Tile) {
+	t.tiles = append(t.tiles, tl)
+}
+
+// Shuffle changes the order in which the tiles not drawn yet will be drawn,
+// so the same seed always produces the same new order
+func (t *TileSet) Shuffle(seed int64) {
+	rn := rand.New(rand.NewSource(seed))
+	rn.Shuffle(len(t.tiles), func(i, j int) {
+		t.tiles[i], t.tiles[j] = t.tiles[j], t.tiles[i]
+	})
+}
+
+// Tiles returns the tiles not drawn yet, in the order they will be drawn
+func (t *TileSet) Tiles() []acquireInterfaces.Tile {
+	return append([]acquireInterfaces.Tile{}, t.tiles...)
+}
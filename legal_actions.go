@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/svera/acquire-sackson-driver/internal/corporation"
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/rules"
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+	"github.com/svera/acquire/tile"
+)
+
+// LegalActions returns every action the passed player can take in the current
+// game state, as a messages.LegalActions. Players not in turn cannot take any action.
+func (b *AcquireDriver) LegalActions(playerNumber int) (interface{}, error) {
+	return b.legalActions(playerNumber)
+}
+
+// legalActions returns every action the passed player can take in the current game state
+func (b *AcquireDriver) legalActions(playerNumber int) (messages.LegalActions, error) {
+	legal := messages.LegalActions{}
+
+	if !b.GameStarted() {
+		return legal, errors.New(GameNotStarted)
+	}
+	if _, exists := b.players[playerNumber]; !exists {
+		return legal, errors.New(NonexistentPlayer)
+	}
+	if !b.isCurrentPlayer(playerNumber) || b.IsGameOver() {
+		return legal, nil
+	}
+
+	pl := b.players[playerNumber]
+	switch b.game.GameStateName() {
+	case acquireInterfaces.PlayTileStateName:
+		for _, tl := range pl.Tiles() {
+			if b.isTilePlayable(tl) {
+				legal.Tiles = append(legal.Tiles, tileToCoords(tl))
+			}
+		}
+	case acquireInterfaces.FoundCorpStateName:
+		for i, corp := range b.corporations[:b.config.Corporations] {
+			if corp.Size() == 0 {
+				legal.Corporations = append(legal.Corporations, i)
+			}
+		}
+	case acquireInterfaces.BuyStockStateName:
+		for _, combination := range rules.StockCombinations(b.stockData(), b.config.MaxBuy, pl.Cash()) {
+			buy := map[string]int{}
+			for _, index := range combination {
+				buy[strconv.Itoa(index)]++
+			}
+			legal.Buy = append(legal.Buy, buy)
+		}
+	case acquireInterfaces.SellTradeStateName:
+		legal.SellTrade = b.sellTradeCombinations(pl)
+	case acquireInterfaces.UntieMergeStateName:
+		for _, corp := range b.game.TiedCorps() {
+			legal.Untie = append(legal.Untie, corp.(*corporation.Corporation).Index())
+		}
+	}
+	legal.ClaimEndGame = !b.game.IsLastRound() && b.endGameConditions()
+	return legal, nil
+}
+
+// stockData returns the data of the corporations the stock purchase rules depend on
+func (b *AcquireDriver) stockData() []rules.Corporation {
+	corps := make([]rules.Corporation, len(b.corporations))
+	for i, corp := range b.corporations {
+		corps[i] = rules.Corporation{Size: corp.Size(), Stock: corp.Stock(), Price: corp.StockPrice()}
+	}
+	return corps
+}
+
+// sellTradeCombinations returns, for every defunct corporation in which the passed
+// player owns shares, the valid amounts of them to be sold and traded. Trades are
+// limited by the acquirer remaining stock, which is shared by all defunct corporations.
+func (b *AcquireDriver) sellTradeCombinations(pl acquireInterfaces.Player) map[string][]messages.SellTradeAmounts {
+	combinations := map[string][]messages.SellTradeAmounts{}
+	maxTrade := 0
+	if acquirer := b.acquirer(); acquirer != nil {
+		maxTrade = acquirer.Stock() * 2
+	}
+
+	for i, corp := range b.corporations {
+		owned := pl.Shares(corp)
+		if !b.game.IsCorporationDefunct(corp) || owned == 0 {
+			continue
+		}
+		amounts := []messages.SellTradeAmounts{}
+		for trade := 0; trade <= owned && trade <= maxTrade; trade += 2 {
+			for sell := 0; sell <= owned-trade; sell++ {
+				amounts = append(amounts, messages.SellTradeAmounts{Sell: sell, Trade: trade})
+			}
+		}
+		combinations[strconv.Itoa(i)] = amounts
+	}
+	return combinations
+}
+
+// acquirer returns the biggest not defunct corporation adjacent to the last
+// played tile, which is the one absorbing the rest in a merge
+func (b *AcquireDriver) acquirer() acquireInterfaces.Corporation {
+	var acquirer acquireInterfaces.Corporation
+	if b.lastTile == nil {
+		return nil
+	}
+	for _, coords := range adjacentCells(b.lastTile) {
+		cell := b.game.Board().Cell(coords.Number(), coords.Letter())
+		if cell.Type() != "corporation" {
+			continue
+		}
+		corp := cell.(*corporation.Corporation)
+		if b.game.IsCorporationDefunct(corp) {
+			continue
+		}
+		if acquirer == nil || corp.Size() > acquirer.Size() {
+			acquirer = corp
+		}
+	}
+	return acquirer
+}
+
+// adjacentCells returns the tiles orthogonally adjacent to the passed one
+// isTilePlayable returns true if the passed tile can be played. Upstream counts all
+// seven corporations when telling whether a tile could found one, so tiles which would
+// found a corporation when all the ones the game allows are active are ruled out here.
+func (b *AcquireDriver) isTilePlayable(tl acquireInterfaces.Tile) bool {
+	return b.game.IsTilePlayable(tl) && (b.corporationsLeft() || !b.foundsCorporation(tl))
+}
+
+// foundsCorporation returns true if playing the passed tile would found a corporation,
+// being next to unincorporated tiles but not to any corporation
+func (b *AcquireDriver) foundsCorporation(tl acquireInterfaces.Tile) bool {
+	unincorporated := false
+	for _, coords := range adjacentCells(tl) {
+		switch b.game.Board().Cell(coords.Number(), coords.Letter()).Type() {
+		case "corporation":
+			return false
+		case "unincorporated":
+			unincorporated = true
+		}
+	}
+	return unincorporated
+}
+
+// corporationsLeft returns true if any of the corporations the game allows can still be founded
+func (b *AcquireDriver) corporationsLeft() bool {
+	for _, corp := range b.corporations[:b.config.Corporations] {
+		if corp.Size() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func adjacentCells(tl acquireInterfaces.Tile) []acquireInterfaces.Tile {
+	cells := []acquireInterfaces.Tile{}
+	for _, c := range rules.ParseCell(tileToCoords(tl)).Adjacent() {
+		cells = append(cells, tile.New(c.Number, rules.Letters[c.Letter:c.Letter+1]))
+	}
+	return cells
+}
+
+// endGameConditions returns true if a corporation has reached the end game size
+// or all active corporations are safe
+func (b *AcquireDriver) endGameConditions() bool {
+	sizes := make([]int, len(b.corporations))
+	for i, corp := range b.corporations {
+		sizes[i] = corp.Size()
+	}
+	return rules.EndGameConditions(sizes, b.config.EndGameCorporationSize, b.config.SafeCorporationSize)
+}
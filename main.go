@@ -3,12 +3,17 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"math/rand"
+	"sort"
+	"time"
 
 	"github.com/svera/acquire"
 	"github.com/svera/acquire-sackson-driver/internal/bots"
 	"github.com/svera/acquire-sackson-driver/internal/corporation"
 	"github.com/svera/acquire-sackson-driver/internal/messages"
 	"github.com/svera/acquire-sackson-driver/internal/player"
+	"github.com/svera/acquire-sackson-driver/internal/replay"
+	"github.com/svera/acquire-sackson-driver/internal/tileset"
 	acquireInterfaces "github.com/svera/acquire/interfaces"
 	"github.com/svera/sackson-server/api"
 )
@@ -16,10 +21,112 @@ import (
 // AcquireDriver implements the driver interface in order to be able to have
 // and acquire game through the turn based game server
 type AcquireDriver struct {
-	game         *acquire.Game
-	players      map[int]acquireInterfaces.Player
-	corporations [7]acquireInterfaces.Corporation
-	history      []messages.I18n
+	game          *acquire.Game
+	players       map[int]acquireInterfaces.Player
+	corporations  [7]acquireInterfaces.Corporation
+	history       []messages.I18n
+	seed          int64
+	ais           []api.AI
+	log           *replay.Log
+	tileset       *tileset.TileSet
+	options       Options
+	lastTile      acquireInterfaces.Tile
+	pendingUndo   *undoRequest
+	pendingMerge  []mergingCorporation
+	merge         *messages.MergeData
+	timeBanks     map[int]time.Duration
+	decisionStart time.Time
+	substitutes   map[int]substitute
+	version       int
+	sent          map[int]messages.Status
+	encodings     map[int]string
+	placements    []messages.Placement
+	playerStats   map[int]*messages.PlayerStatistics
+	corpStats     [7][]messages.Lifetime
+	config        GameConfig
+	final         *messages.FinalResult
+	spare         *AcquireDriver
+	market        *rand.Rand
+}
+
+// Options holds the settings a game can be started with
+type Options struct {
+	// Seed fixes the order in which tiles are drawn, and seeds the bots created for the
+	// game. A random seed is used if it is zero.
+	Seed int64 `json:"sed,omitempty"`
+	// HideCash hides the cash of each player from its rivals until the game ends,
+	// as allowed by the official rules.
+	HideCash bool `json:"hcs,omitempty"`
+	// HideShares hides the shares owned by each player from its rivals until the game ends.
+	HideShares bool `json:"hsh,omitempty"`
+	// Corporations replaces the default corporations. If set, it must contain seven of them.
+	Corporations []CorporationConfig `json:"cor,omitempty"`
+	// Undo sets when players can undo their last action: UndoNever (default),
+	// UndoTurn or UndoUnanimous.
+	Undo string `json:"und,omitempty"`
+	// ActionTime is the time players have for every decision, and TimeBank the extra time
+	// each one has for the whole game. When both run out, a bot decides for the player.
+	// Players have no time limit if both are zero. Both are encoded in milliseconds.
+	ActionTime time.Duration `json:"-"`
+	TimeBank   time.Duration `json:"-"`
+	// TimeoutBot is the level of the bot which plays for players who run out of time.
+	// Defaults to "greedy".
+	TimeoutBot string `json:"tbt,omitempty"`
+	// Substitute is the level of the bot which takes the seat of players who leave the game.
+	// If empty, they are removed from it.
+	Substitute string `json:"sub,omitempty"`
+	// RejoinActions is the maximum number of actions that can be taken since a player
+	// was removed for it to be able to rejoin the game. Zero means no limit.
+	RejoinActions int `json:"rej,omitempty"`
+	// Placements adds to the status who placed every tile on the board and when.
+	Placements bool `json:"plc,omitempty"`
+	// Config holds the house rules of the game.
+	Config GameConfig `json:"cfg"`
+	// Variant sets the rules variant the game is played with. Only the house variant
+	// VariantHouseTwoPlayers is supported, the official rules being used if empty.
+	Variant string `json:"var,omitempty"`
+	// Teams assigns every player number to a team, for games played in teams of the
+	// same size. Teammates see each other's hand and holdings, and are ranked together.
+	Teams map[int]string `json:"tms,omitempty"`
+}
+
+// encodedOptions is the JSON encoding of Options, with times in milliseconds
+type encodedOptions struct {
+	*optionsFields
+	ActionTime int64 `json:"act,omitempty"`
+	TimeBank   int64 `json:"bnk,omitempty"`
+}
+
+// optionsFields has the same fields as Options, without its JSON methods
+type optionsFields Options
+
+// MarshalJSON encodes the options with their times in milliseconds
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodedOptions{
+		optionsFields: (*optionsFields)(&o),
+		ActionTime:    int64(o.ActionTime / time.Millisecond),
+		TimeBank:      int64(o.TimeBank / time.Millisecond),
+	})
+}
+
+// UnmarshalJSON decodes options with their times in milliseconds
+func (o *Options) UnmarshalJSON(data []byte) error {
+	encoded := encodedOptions{optionsFields: (*optionsFields)(o)}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	o.ActionTime = time.Duration(encoded.ActionTime) * time.Millisecond
+	o.TimeBank = time.Duration(encoded.TimeBank) * time.Millisecond
+	return nil
+}
+
+// CorporationConfig defines a corporation used in a game
+type CorporationConfig struct {
+	Name string `json:"nam"`
+	// ID identifies the corporation in clients, which can use it to choose its color or logo
+	ID string `json:"id"`
+	// Tier is the corporation price range: "cheap", "medium" or "expensive"
+	Tier string `json:"tie"`
 }
 
 // NotEndGame defines the message returned when a player claims wrongly that end game conditions have been met
@@ -40,60 +147,148 @@ const NonexistentPlayer = "nonexistent_player"
 // CorporationNotFound is an error returned when someone tries to use a non existent corporation
 const CorporationNotFound = "corporation_not_found"
 
+// WrongAIParams is an error returned when the params passed to create an AI are not valid
+const WrongAIParams = "wrong_ai_params"
+
+// NotYourTurn is an error returned when someone tries to play out of its turn
+const NotYourTurn = "not_your_turn"
+
+// WrongCorporations is an error returned when the corporations set for a game are not valid
+const WrongCorporations = "wrong_corporations"
+
+// WrongOptions is an error returned when the options a game is started with can not be parsed
+const WrongOptions = "wrong_options"
+
+// DuplicatedPlayerName is an error returned when trying to start a game in which two players have the same name
+const DuplicatedPlayerName = "duplicated_player_name"
+
 // New initializes a new AcquireDriver instance
 func New() api.Driver {
 	return &AcquireDriver{
 		corporations: defaultCorporations(),
+		seed:         time.Now().UnixNano(),
 	}
 }
 
 // Execute gets an input JSON-encoded message and parses it, executing
-// whatever actions are required by it
+// whatever actions are required by it. Actions are only accepted from the
+// player in turn, and attributed to the name its seat was registered with.
+// Seats played by bots take their turns right after it, until a player has to decide.
 func (b *AcquireDriver) Execute(action api.Action) error {
+	if err := b.execute(action); err != nil {
+		return err
+	}
+	b.playSubstitutes()
+	return nil
+}
+
+// execute executes the passed action, but not the turns of the seats played by bots
+// it may give the turn to, as a game rebuilt from its log plays them from there
+func (b *AcquireDriver) execute(action api.Action) error {
 	var err error
+	var playerNumber int
+	var name string
+	var hands map[int][]string
+	var placed int
+	var holdings map[int]holding
+
+	if !b.GameStarted() {
+		return actionError(errors.New(GameNotStarted), action.Type)
+	}
 	b.history = nil
+	playerNumber = b.game.CurrentPlayer().Number()
+
+	if action.Type == messages.TypeUndo || action.Type == messages.TypeUndoVote {
+		if err = b.undo(action); err != nil {
+			return actionError(err, action.Type)
+		}
+		b.version++
+		return nil
+	}
+	if b.pendingUndo != nil {
+		return actionError(newError(UndoPending, "", nil), action.Type)
+	}
+
+	if err = b.checkTurn(action.PlayerName); err != nil {
+		return actionError(err, action.Type)
+	}
+	name = b.playerName(playerNumber)
+	hands, placed, holdings = b.hands(), len(b.placements), b.holdings()
+
+	if err = b.apply(name, action); err != nil {
+		return actionError(err, action.Type)
+	}
+	b.settle(holdings)
+	b.updateStatistics(hands, len(b.placements)-placed)
+	b.stopClock(playerNumber)
+	err = b.passNeutrals()
+	b.record(playerNumber, action)
+	if err != nil {
+		// The action was taken, but the game cannot go on
+		return actionError(err, action.Type)
+	}
+	return nil
+}
+
+// apply executes the passed action on behalf of the player in turn, whose name is passed
+func (b *AcquireDriver) apply(name string, action api.Action) error {
+	var err error
 
 	switch action.Type {
 	case messages.TypePlayTile:
 		var parsed messages.PlayTile
 		if err = json.Unmarshal(action.Params, &parsed); err == nil {
-			err = b.playTile(action.PlayerName, parsed)
+			err = b.playTile(name, parsed)
 		}
 	case messages.TypeFoundCorporation:
 		var parsed messages.NewCorp
 		if err = json.Unmarshal(action.Params, &parsed); err == nil {
-			err = b.foundCorporation(action.PlayerName, parsed)
+			err = b.foundCorporation(name, parsed)
 		}
 	case messages.TypeBuyStock:
 		var parsed messages.Buy
 		if err = json.Unmarshal(action.Params, &parsed); err == nil {
-			err = b.buyStock(action.PlayerName, parsed)
+			err = b.buyStock(name, parsed)
 		}
 	case messages.TypeSellTrade:
 		var parsed messages.SellTrade
 		if err = json.Unmarshal(action.Params, &parsed); err == nil {
-			err = b.sellTrade(action.PlayerName, parsed)
+			err = b.sellTrade(name, parsed)
 		}
 	case messages.TypeUntieMerge:
 		var parsed messages.UntieMerge
 		if err = json.Unmarshal(action.Params, &parsed); err == nil {
-			err = b.untieMerge(action.PlayerName, parsed)
+			err = b.untieMerge(name, parsed)
 		}
 	case messages.TypeEndGame:
-		err = b.claimEndGame(action.PlayerName)
+		err = b.claimEndGame(name)
 	default:
-		err = errors.New(WrongMessage)
+		err = newError(WrongMessage, "typ", map[string]string{"type": action.Type})
 	}
-
 	return err
 }
 
+// checkTurn returns an error if the passed client is not the player in turn
+func (b *AcquireDriver) checkTurn(clientName string) error {
+	n, exists := b.playerNumber(clientName)
+	if !exists {
+		return newError(NonexistentPlayer, "", map[string]string{"player": clientName})
+	}
+	if !b.isCurrentPlayer(n) {
+		return newError(NotYourTurn, "", map[string]string{"player": clientName})
+	}
+	return nil
+}
+
 // CurrentPlayersNumbers returns a slice containing the number of each player currently in turn
 func (b *AcquireDriver) CurrentPlayersNumbers() ([]int, error) {
 	currentPlayersNumbers := []int{}
 	if !b.GameStarted() {
 		return currentPlayersNumbers, errors.New(GameNotStarted)
 	}
+	if b.pendingUndo != nil {
+		return b.undoVoters(), nil
+	}
 	currentPlayersNumbers = append(currentPlayersNumbers, b.game.CurrentPlayer().Number())
 	return currentPlayersNumbers, nil
 }
@@ -123,45 +318,169 @@ func (b *AcquireDriver) playersShares(playerNumber int) [7]int {
 
 // RemovePlayer removes a player from the game
 func (b *AcquireDriver) RemovePlayer(number int) error {
+	if err := b.removePlayer(number); err != nil {
+		return err
+	}
+	b.playSubstitutes()
+	return nil
+}
+
+// removePlayer removes a player from the game, or hands its seat to a bot if the
+// game options set one, without playing the turns of the seats played by bots
+func (b *AcquireDriver) removePlayer(number int) error {
 	if _, exists := b.players[number]; !exists {
 		return errors.New(NonexistentPlayer)
 	}
+	if b.options.Substitute != "" && !b.IsGameOver() {
+		return b.substitutePlayer(number, b.options.Substitute)
+	}
 	playerName := b.players[number].(*player.Player).Name()
+	holdings := b.holdings()
 	b.game.RemovePlayer(b.players[number])
 	delete(b.players, number)
+	b.settle(holdings)
 	b.history = append([]messages.I18n{}, messages.I18n{
 		Key: "game.history.player_left",
 		Arguments: map[string]string{
 			"player": playerName,
 		},
 	})
+	b.cancelUndo()
+	b.record(number, api.Action{PlayerName: playerName, Type: messages.TypeClientOut})
 	return nil
 }
 
 // StartGame starts a new Acquire game
 func (b *AcquireDriver) StartGame(clientNames map[int]string) error {
+	return b.start(clientNames, Options{}, nil)
+}
+
+// StartGameWithOptions starts a new Acquire game using the passed JSON encoded options.
+// All of them are optional, as in:
+//
+//   {
+//     "sed": 42,               // Seed
+//     "hcs": true,             // Hide cash
+//     "hsh": true,             // Hide shares
+//     "cor": [                 // Corporations
+//       {"nam": "Sackson", "id": "sackson", "tie": "cheap"},
+//       ...
+//     ],
+//     "und": "turn",           // Undo
+//     "act": 30000,            // Action time, in milliseconds
+//     "bnk": 600000,           // Time bank, in milliseconds
+//     "tbt": "greedy",         // Timeout bot level
+//     "sub": "greedy",         // Substitute bot level
+//     "rej": 10,               // Rejoin actions
+//     "plc": true,             // Placements
+//     "cfg": {                 // House rules
+//       "csh": 6000,           // Starting cash
+//       "hnd": 6,              // Hand size
+//       "buy": 3,              // Max shares bought per turn
+//       "end": 41,             // End game corporation size
+//       "saf": 11,             // Safe corporation size
+//       "cor": 7,              // Corporations
+//       "min": 3,              // Min players
+//       "max": 6               // Max players
+//     },
+//     "var": "house_two_players", // Variant
+//     "tms": {"0": "red", "1": "blue", "2": "red", "3": "blue"} // Teams
+//   }
+func (b *AcquireDriver) StartGameWithOptions(clientNames map[int]string, options json.RawMessage) error {
+	var parsed Options
+	if err := json.Unmarshal(options, &parsed); err != nil {
+		return errors.New(WrongOptions)
+	}
+	return b.start(clientNames, parsed, nil)
+}
+
+// start starts a new Acquire game using the passed options. If hands are passed, players
+// are given them instead of the ones dealt by the engine, which must have the same tiles.
+func (b *AcquireDriver) start(clientNames map[int]string, options Options, hands map[int][]string) error {
 	var err error
 
 	if b.GameStarted() {
-		err = errors.New(GameAlreadyStarted)
+		return errors.New(GameAlreadyStarted)
 	}
+	config := options.Config.withDefaults()
+	if err = config.Validate(); err != nil {
+		return err
+	}
+	if err = validateTeams(clientNames, options); err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for _, name := range clientNames {
+		if names[name] {
+			return errors.New(DuplicatedPlayerName)
+		}
+		names[name] = true
+	}
+	seats, err := b.seats(clientNames, options.Variant)
+	if err != nil {
+		return err
+	}
+	if len(seats) < config.MinPlayers || len(seats) > config.MaxPlayers {
+		return errors.New(WrongPlayersNumber)
+	}
+	b.config = config
 
-	b.addPlayers(clientNames)
+	corporations := b.corporations
+	if len(options.Corporations) > 0 {
+		if corporations, err = newCorporations(options.Corporations); err != nil {
+			return err
+		}
+	}
+	for _, level := range []string{options.TimeoutBot, options.Substitute} {
+		if level == "" {
+			continue
+		}
+		if _, err = bots.Create(bots.Params{Level: level}, b.seed); err != nil {
+			return err
+		}
+	}
+	if options.Seed != 0 {
+		b.seed = options.Seed
+		b.seedAIs()
+	}
+	b.options = options
+	b.addPlayers(seats)
 
-	if b.game, err = acquire.New(b.players, acquire.Optional{Corporations: b.corporations}); err == nil {
-		b.history = append(b.history, messages.I18n{
-			Key: "game.history.starter_player",
-			Arguments: map[string]string{
-				"player": b.currentPlayerName(),
-			},
-		})
+	b.tileset = tileset.New(b.seed)
+	optional := acquire.Optional{
+		Corporations: corporations,
+		TileSet:      b.tileset,
 	}
-	return err
+	b.log = b.newLog(clientNames, options, b.tileset.Tiles())
+	if b.game, err = acquire.New(b.players, optional); err != nil {
+		return err
+	}
+	b.corporations = corporations
+	if hands != nil {
+		if err = b.dealHands(hands); err != nil {
+			return err
+		}
+	}
+	b.recordStart()
+	b.applyConfig()
+	b.startClocks()
+	b.startStatistics()
+	b.history = append(b.history, messages.I18n{
+		Key: "game.history.starter_player",
+		Arguments: map[string]string{
+			"player": b.currentPlayerName(),
+		},
+	})
+	b.market = rand.New(rand.NewSource(b.seed))
+	return b.passNeutrals()
 }
 
 // addPlayers adds players to the game
 func (b *AcquireDriver) addPlayers(clientNames map[int]string) {
 	b.players = make(map[int]acquireInterfaces.Player)
+	b.substitutes = map[int]substitute{}
+	b.sent = map[int]messages.Status{}
+	b.encodings = map[int]string{}
 
 	for n, playerName := range clientNames {
 		b.players[n] = player.New(playerName, n)
@@ -169,8 +488,31 @@ func (b *AcquireDriver) addPlayers(clientNames map[int]string) {
 }
 
 func (b *AcquireDriver) currentPlayerName() string {
-	currentPlayerNumber := b.game.CurrentPlayer().Number()
-	return b.players[currentPlayerNumber].(*player.Player).Name()
+	return b.playerName(b.game.CurrentPlayer().Number())
+}
+
+// playerNumber returns the number of the player with the passed name
+func (b *AcquireDriver) playerNumber(name string) (int, bool) {
+	for n, pl := range b.players {
+		if pl.(*player.Player).Name() == name {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+func (b *AcquireDriver) playerName(n int) string {
+	return b.players[n].(*player.Player).Name()
+}
+
+// playerNumbers returns the numbers of the players in the game in ascending order
+func (b *AcquireDriver) playerNumbers() []int {
+	numbers := make([]int, 0, len(b.players))
+	for n := range b.players {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers
 }
 
 // IsGameOver returns true if the game has reached its end or there are not
@@ -184,37 +526,113 @@ func (b *AcquireDriver) IsGameOver() bool {
 	return false
 }
 
-// CreateAI create an instance of an AI of the passed level
+// Seed returns the seed used to shuffle the tiles, which can be passed to
+// StartGameWithOptions for another game to draw them in the same order
+func (b *AcquireDriver) Seed() int64 {
+	return b.seed
+}
+
+// CreateAI create an instance of an AI of the passed level. Params can be
+// the level name, or a map with the following keys:
+//
+//   {
+//     "lvl": "mcts", // Level name
+//     "itr": 1000,   // Playouts per decision
+//     "tim": 2000    // Time budget per decision, in milliseconds
+//   }
 func (b *AcquireDriver) CreateAI(params interface{}) (api.AI, error) {
 	var err error
 	var ai api.AI
-	if level, ok := params.(string); ok {
-		if ai, err = bots.Create(level); err == nil {
-			return ai, nil
+	var aiParams bots.Params
+
+	switch p := params.(type) {
+	case string:
+		aiParams.Level = p
+	case bots.Params:
+		aiParams = p
+	case map[string]interface{}:
+		if aiParams, err = parseAIParams(p); err != nil {
+			return nil, err
 		}
-		return nil, err
+	default:
+		return nil, errors.New(WrongAIParams)
 	}
-	panic("Expecting string in CreateAI parameter")
+
+	if ai, err = bots.Create(aiParams, b.aiSeed(len(b.ais))); err == nil {
+		b.ais = append(b.ais, ai)
+		return ai, nil
+	}
+	return nil, err
 }
 
-func defaultCorporations() [7]acquireInterfaces.Corporation {
-	var corporations [7]acquireInterfaces.Corporation
-	corpsParams := [7]string{
-		"Sackson",
-		"Zeta",
-		"Hydra",
-		"Fusion",
-		"America",
-		"Phoenix",
-		"Quantum",
+func parseAIParams(params map[string]interface{}) (bots.Params, error) {
+	var aiParams bots.Params
+	var ok bool
+
+	if aiParams.Level, ok = params["lvl"].(string); !ok {
+		return aiParams, errors.New(WrongAIParams)
+	}
+	if iterations, ok := params["itr"].(float64); ok {
+		aiParams.Iterations = int(iterations)
+	}
+	if budget, ok := params["tim"].(float64); ok {
+		aiParams.Budget = time.Duration(budget) * time.Millisecond
 	}
+	return aiParams, nil
+}
+
+// aiSeed derives the seed of every bot from the game one, so each bot
+// takes different decisions but all of them are reproducible. Seeds are odd
+// offsets from the game one, as the even ones are left for the bots created during the game.
+func (b *AcquireDriver) aiSeed(n int) int64 {
+	return b.seed + 2*int64(n) + 1
+}
 
-	for i, corpName := range corpsParams {
-		corporations[i] = corporation.New(corpName, i)
+// seedAIs reseeds the bots created before the game seed was set
+func (b *AcquireDriver) seedAIs() {
+	for i, ai := range b.ais {
+		if seeder, ok := ai.(bots.Seeder); ok {
+			seeder.Seed(b.aiSeed(i))
+		}
 	}
+}
+
+// defaultCorporationsConfig holds the corporations used when a game does not set its own ones
+var defaultCorporationsConfig = []CorporationConfig{
+	{Name: "Sackson", ID: "sackson", Tier: "cheap"},
+	{Name: "Zeta", ID: "zeta", Tier: "cheap"},
+	{Name: "Hydra", ID: "hydra", Tier: "medium"},
+	{Name: "Fusion", ID: "fusion", Tier: "medium"},
+	{Name: "America", ID: "america", Tier: "medium"},
+	{Name: "Phoenix", ID: "phoenix", Tier: "expensive"},
+	{Name: "Quantum", ID: "quantum", Tier: "expensive"},
+}
+
+func defaultCorporations() [7]acquireInterfaces.Corporation {
+	corporations, _ := newCorporations(defaultCorporationsConfig)
 	return corporations
 }
 
+// newCorporations creates the corporations defined in the passed config,
+// which must contain seven of them with different names
+func newCorporations(config []CorporationConfig) ([7]acquireInterfaces.Corporation, error) {
+	var corporations [7]acquireInterfaces.Corporation
+	names := map[string]bool{}
+
+	if len(config) != len(corporations) {
+		return corporations, errors.New(WrongCorporations)
+	}
+	for i, corp := range config {
+		tier, err := corporation.ParseTier(corp.Tier)
+		if err != nil || corp.Name == "" || names[corp.Name] {
+			return corporations, errors.New(WrongCorporations)
+		}
+		names[corp.Name] = true
+		corporations[i] = corporation.New(corp.Name, corp.ID, tier, i)
+	}
+	return corporations, nil
+}
+
 // Name returns the name of the driver, used to identify which game it implements
 func (b *AcquireDriver) Name() string {
 	return "acquire"
@@ -2,11 +2,40 @@ package main
 
 import (
 	"encoding/json"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/replay"
 	"github.com/svera/sackson-server/api"
 )
 
+// startGame starts a game with the passed options, encoded as the server passes them
+func startGame(driver *AcquireDriver, playerNames map[int]string, options Options) error {
+	encoded, _ := json.Marshal(options)
+	return driver.StartGameWithOptions(playerNames, encoded)
+}
+
+// execute makes the player in turn execute the passed action, failing the test if it is rejected
+func execute(t *testing.T, driver *AcquireDriver, actionType string, params interface{}) {
+	t.Helper()
+	encoded, _ := json.Marshal(params)
+	action := api.Action{PlayerName: driver.currentPlayerName(), Type: actionType, Params: encoded}
+	if err := driver.Execute(action); err != nil {
+		t.Fatalf("Driver must accept action %s %s, got %v", actionType, encoded, err)
+	}
+}
+
+// playTile makes the player in turn play the passed tile, which is given to it first
+func playTile(t *testing.T, driver *AcquireDriver, coords string) {
+	t.Helper()
+	tl, _ := coordsToTile(coords)
+	driver.game.CurrentPlayer().PickTile(tl)
+	execute(t, driver, messages.TypePlayTile, messages.PlayTile{Tile: coords})
+}
+
 func TestParseNonExistingTypeMessage(t *testing.T) {
 	driver := New().(*AcquireDriver)
 	err := driver.Execute(api.Action{PlayerName: "Test client", Type: "err", Params: json.RawMessage{}})
@@ -23,32 +52,32 @@ func TestParseWrongTypeMessage(t *testing.T) {
 	data := []byte(`{"aaa": "bbb"}`)
 	raw := (json.RawMessage)(data)
 
-	err := driver.Execute(api.Action{PlayerName: "Test client", Type: "ply", Params: raw})
+	err := driver.Execute(api.Action{PlayerName: "test1", Type: "ply", Params: raw})
 	if err == nil {
 		t.Errorf("Driver must return an error when receiving a malformed message")
 	}
 
-	err = driver.Execute(api.Action{PlayerName: "Test client", Type: "ncp", Params: raw})
+	err = driver.Execute(api.Action{PlayerName: "test1", Type: "ncp", Params: raw})
 	if err == nil {
 		t.Errorf("Driver must return an error when receiving a malformed message")
 	}
 
-	err = driver.Execute(api.Action{PlayerName: "Test client", Type: "buy", Params: raw})
+	err = driver.Execute(api.Action{PlayerName: "test1", Type: "buy", Params: raw})
 	if err == nil {
 		t.Errorf("Driver must return an error when receiving a malformed message")
 	}
 
-	err = driver.Execute(api.Action{PlayerName: "Test client", Type: "sel", Params: raw})
+	err = driver.Execute(api.Action{PlayerName: "test1", Type: "sel", Params: raw})
 	if err == nil {
 		t.Errorf("Driver must return an error when receiving a malformed message")
 	}
 
-	err = driver.Execute(api.Action{PlayerName: "Test client", Type: "unt", Params: raw})
+	err = driver.Execute(api.Action{PlayerName: "test1", Type: "unt", Params: raw})
 	if err == nil {
 		t.Errorf("Driver must return an error when receiving a malformed message")
 	}
 
-	err = driver.Execute(api.Action{PlayerName: "Test client", Type: "end", Params: raw})
+	err = driver.Execute(api.Action{PlayerName: "test1", Type: "end", Params: raw})
 	if err == nil {
 		t.Errorf("Driver must return an error when receiving a malformed message")
 	}
@@ -90,6 +119,57 @@ func TestStartGameWithEnoughPlayers(t *testing.T) {
 	}
 }
 
+func TestStartGameWithDuplicatedNames(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test1"}
+
+	if err := driver.StartGame(playerNames); err == nil || err.Error() != DuplicatedPlayerName {
+		t.Errorf("Driver must not start a game in which two players have the same name, got %v", err)
+	}
+}
+
+func TestExecuteBeforeStartingGame(t *testing.T) {
+	driver := New().(*AcquireDriver)
+
+	err := driver.Execute(api.Action{PlayerName: "test1", Type: messages.TypePlayTile, Params: json.RawMessage(`{"til": "1A"}`)})
+	if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != GameNotStarted {
+		t.Errorf("Driver must reject actions before the game starts, got %v", err)
+	}
+}
+
+func TestStartGameWithEncodedOptions(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	if err := driver.StartGameWithOptions(playerNames, json.RawMessage(`{"sed": 1`)); err == nil || err.Error() != WrongOptions {
+		t.Errorf("Driver must not start a game with options which can not be parsed, got %v", err)
+	}
+	options := json.RawMessage(`{"sed": 7, "act": 30000, "cfg": {"buy": 2}}`)
+	if err := driver.StartGameWithOptions(playerNames, options); err != nil {
+		t.Fatalf("Driver must start a game with encoded options, got %v", err)
+	}
+	if driver.Seed() != 7 || driver.options.ActionTime != 30*time.Second || driver.config.MaxBuy != 2 {
+		t.Errorf("Driver must start the game with the passed options, got %+v", driver.options)
+	}
+}
+
+func TestSameSeedDrawsSameTiles(t *testing.T) {
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+	drivers := [2]*AcquireDriver{New().(*AcquireDriver), New().(*AcquireDriver)}
+
+	for _, driver := range drivers {
+		startGame(driver, playerNames, Options{Seed: 7})
+	}
+	for n := range playerNames {
+		if !reflect.DeepEqual(drivers[0].tilesData(drivers[0].players[n]), drivers[1].tilesData(drivers[1].players[n])) {
+			t.Errorf("Games started with the same seed must deal the same hands")
+		}
+	}
+	if !reflect.DeepEqual(drivers[0].tileset.Tiles(), drivers[1].tileset.Tiles()) {
+		t.Errorf("Games started with the same seed must draw the tiles in the same order")
+	}
+}
+
 func TestStatusWithGameStarted(t *testing.T) {
 	driver := New().(*AcquireDriver)
 	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
@@ -117,3 +197,810 @@ func TestStatusForNonexistentPlayer(t *testing.T) {
 		t.Errorf("Driver must return an error when trying to get the game status of an nonexistent player")
 	}
 }
+
+func TestRankGivesSamePositionToTiedPlayers(t *testing.T) {
+	ranking := rank([]messages.FinalPlayerData{
+		{Name: "test1", Total: 6000},
+		{Name: "test2", Total: 9000},
+		{Name: "test3", Total: 6000},
+	})
+	expected := []messages.RankData{
+		{Position: 1, Name: "test2", Total: 9000},
+		{Position: 2, Name: "test1", Total: 6000},
+		{Position: 2, Name: "test3", Total: 6000},
+	}
+	if !reflect.DeepEqual(ranking, expected) {
+		t.Errorf("Expected ranking %v, got %v", expected, ranking)
+	}
+}
+
+func TestFinalResultWhenNotEnoughPlayersAreLeft(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	driver.RemovePlayer(0)
+	status, _ := driver.Status(1)
+	final := status.(messages.Status).Final
+	if !driver.IsGameOver() || final == nil {
+		t.Fatalf("Driver must return the final result of a game without enough players left")
+	}
+	for _, pl := range final.Players {
+		if pl.Total != pl.Cash || pl.Bonuses != 0 {
+			t.Errorf("Players of a game not settled must keep their cash, got %+v", pl)
+		}
+	}
+}
+
+func TestBuyNegativeAmountOfShares(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1})
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params})
+
+	params, _ = json.Marshal(messages.Buy{CorporationsIndexes: map[string]int{"0": -1}})
+	err := driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypeBuyStock, Params: params})
+	if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != WrongAmount {
+		t.Errorf("Driver must not allow buying a negative amount of shares, got %v", err)
+	}
+}
+
+func TestRebuildBeyondRecordedActions(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	if _, err := driver.Rebuild(1); err == nil {
+		t.Errorf("Driver must return an error when trying to rebuild a game beyond its recorded actions")
+	}
+}
+
+func TestRebuildPlayedGame(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3", 3: "test4"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Config: GameConfig{HandSize: 5}})
+	for i := 0; i < 6; i++ {
+		current, _ := driver.CurrentPlayersNumbers()
+		legal, _ := driver.legalActions(current[0])
+		params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+		driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params})
+		driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypeBuyStock, Params: json.RawMessage(`{"cor": {}}`)})
+	}
+	log, _ := driver.Log()
+	rebuilt, err := RebuildFromLog(log, len(driver.log.Entries))
+	if err != nil {
+		t.Fatalf("Driver must rebuild a played game, got %v", err)
+	}
+	if !reflect.DeepEqual(rebuilt.(*AcquireDriver).snapshot(), driver.snapshot()) {
+		t.Errorf("Rebuilt game must be the same as the original one")
+	}
+}
+
+func TestRestoreUnsupportedSnapshot(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	if err := driver.Restore([]byte(`{"ver": 0}`)); err == nil || err.Error() != UnsupportedSnapshot {
+		t.Errorf("Driver must return an error when trying to restore a snapshot with an unknown version")
+	}
+}
+
+func TestRestoreKeepsTimeBanks(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, TimeBank: time.Minute})
+	driver.timeBanks[1] = 30 * time.Second
+	data, err := driver.Snapshot()
+	if err != nil {
+		t.Fatalf("Driver must save a running game, got %v", err)
+	}
+	restored := New().(*AcquireDriver)
+	if err = restored.Restore(data); err != nil {
+		t.Fatalf("Driver must restore a saved game, got %v", err)
+	}
+	if restored.timeBanks[1] != 30*time.Second || restored.timeBanks[0] != time.Minute {
+		t.Errorf("Restored game must keep the time left in every time bank, got %v", restored.timeBanks)
+	}
+}
+
+func TestCreateAIWithWrongParams(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	if _, err := driver.CreateAI(3); err == nil {
+		t.Errorf("Driver must return an error when trying to create an AI with params of a wrong type")
+	}
+	if _, err := driver.CreateAI(map[string]interface{}{"itr": 10.0}); err == nil {
+		t.Errorf("Driver must return an error when trying to create an AI without level")
+	}
+}
+
+func TestCreateAIWithSearchBudget(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	if _, err := driver.CreateAI(map[string]interface{}{"lvl": "mcts", "itr": 10.0, "tim": 50.0}); err != nil {
+		t.Errorf("Driver must not return an error when trying to create an AI with a search budget")
+	}
+}
+
+func TestSpectatorStatusWithGameStarted(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	status, err := driver.SpectatorStatus(false)
+	if err != nil {
+		t.Errorf("Driver must not return an error when trying to get the spectator status of a started game")
+	}
+	if st := status.(messages.Status); len(st.Hand) != 0 || len(st.RivalsInfo) != 3 {
+		t.Errorf("Spectator status must include an empty hand and must list all players as rivals")
+	}
+}
+
+func TestStatusHidesRivalsCash(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{HideCash: true})
+	status, _ := driver.Status(0)
+	st := status.(messages.Status)
+	if st.PlayerInfo.CashHidden || st.PlayerInfo.Cash == 0 {
+		t.Errorf("Player own cash must not be hidden")
+	}
+	for _, rival := range st.RivalsInfo {
+		if !rival.CashHidden || rival.Cash != 0 {
+			t.Errorf("Rivals cash must be hidden")
+		}
+	}
+}
+
+func TestStartGameWithWrongCorporations(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+	options := Options{
+		Corporations: []CorporationConfig{
+			{Name: "Tower", ID: "tower", Tier: "cheap"},
+			{Name: "Luxor", ID: "luxor", Tier: "luxury"},
+		},
+	}
+
+	if err := startGame(driver, playerNames, options); err == nil {
+		t.Errorf("Driver must return an error when trying to start a game with a wrong corporations set")
+	}
+}
+
+func TestExecuteReturnsDriverError(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	err := driver.Execute(api.Action{PlayerName: "test1", Type: "ncp", Params: json.RawMessage(`{"cor": 9}`)})
+	driverErr, ok := err.(*DriverError)
+	if !ok {
+		t.Fatalf("Driver must return a DriverError when rejecting an action, got %v", err)
+	}
+	if driverErr.Code != CorporationNotFound || driverErr.Action != "ncp" || driverErr.Param != "cor" {
+		t.Errorf("Unexpected error data: %+v", driverErr)
+	}
+	if driverErr.I18n().Key != "game.error.corporation_not_found" {
+		t.Errorf("Unexpected error translation key: %s", driverErr.I18n().Key)
+	}
+}
+
+func TestLegalActionsForPlayerNotInTurn(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	current, _ := driver.CurrentPlayersNumbers()
+	for n := range playerNames {
+		actions, err := driver.LegalActions(n)
+		if err != nil {
+			t.Errorf("Driver must not return an error when getting the legal actions of an existing player")
+		}
+		legal := actions.(messages.LegalActions)
+		if n != current[0] && len(legal.Tiles) > 0 {
+			t.Errorf("Players not in turn must not have legal tiles to play")
+		}
+		if n == current[0] && len(legal.Tiles) == 0 {
+			t.Errorf("Player in turn must have legal tiles to play at the beginning of the game")
+		}
+	}
+}
+
+func TestPreviewDoesNotChangeGame(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+
+	preview, err := driver.Preview(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params})
+	if err != nil || !preview.(messages.Preview).Valid {
+		t.Errorf("Driver must preview a legal action without errors, got %v", err)
+	}
+	if len(driver.log.Entries) != 0 {
+		t.Errorf("Previewing an action must not execute it")
+	}
+}
+
+func TestPreviewKeepsCopyWhenPreviewedActionIsExecuted(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	action := api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params}
+
+	driver.Preview(action)
+	spare := driver.spare
+	if err := driver.Execute(action); err != nil {
+		t.Fatalf("Driver must execute a previewed action, got %v", err)
+	}
+	params, _ = json.Marshal(messages.Buy{CorporationsIndexes: map[string]int{}})
+	preview, err := driver.preview(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypeBuyStock, Params: params})
+	if err != nil || !preview.Valid {
+		t.Errorf("Driver must preview a legal action without errors, got %v", err)
+	}
+	if driver.spare != spare {
+		t.Errorf("Driver must keep previewing on the same copy while it follows the game")
+	}
+}
+
+func TestUndoNotAllowedByDefault(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	name := playerNames[current[0]]
+
+	driver.Execute(api.Action{PlayerName: name, Type: messages.TypePlayTile, Params: params})
+	err := driver.Execute(api.Action{PlayerName: name, Type: messages.TypeUndo})
+	if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != UndoNotAllowed {
+		t.Errorf("Driver must not allow undoing actions if the game options do not say so, got %v", err)
+	}
+}
+
+func TestUndoWithinTurn(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Undo: UndoTurn})
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	name := playerNames[current[0]]
+
+	if err := driver.Execute(api.Action{PlayerName: name, Type: messages.TypePlayTile, Params: params}); err != nil {
+		t.Fatalf("Driver must accept a legal tile, got %v", err)
+	}
+	if err := driver.Execute(api.Action{PlayerName: name, Type: messages.TypeUndo}); err != nil {
+		t.Errorf("Driver must allow undoing the last action within the same turn, got %v", err)
+	}
+	if len(driver.log.Entries) != 0 {
+		t.Errorf("Undone action must be removed from the log")
+	}
+	if history := driver.history; len(history) != 2 || history[0].Key != "game.history.starter_player" || history[1].Key != "game.history.undone" {
+		t.Errorf("History must be the one before the undone action followed by the undo, got %v", history)
+	}
+	if hand := driver.tilesData(driver.players[current[0]]); !hand[legal.Tiles[0]] {
+		t.Errorf("Undone tile must be back in the hand of the player")
+	}
+}
+
+func TestUnanimousUndo(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Undo: UndoUnanimous})
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	name := playerNames[current[0]]
+	voter, bot := (current[0]+1)%3, (current[0]+2)%3
+
+	driver.SubstitutePlayer(bot, "greedy")
+	driver.Execute(api.Action{PlayerName: name, Type: messages.TypePlayTile, Params: params})
+	if err := driver.Execute(api.Action{PlayerName: name, Type: messages.TypeUndo}); err != nil {
+		t.Fatalf("Driver must accept an undo request, got %v", err)
+	}
+	if voters, _ := driver.CurrentPlayersNumbers(); !reflect.DeepEqual(voters, []int{voter}) {
+		t.Errorf("Only human players must vote an undo, got %v", voters)
+	}
+	params, _ = json.Marshal(messages.Buy{CorporationsIndexes: map[string]int{}})
+	err := driver.Execute(api.Action{PlayerName: name, Type: messages.TypeBuyStock, Params: params})
+	if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != UndoPending {
+		t.Errorf("Driver must not accept game actions while an undo is pending, got %v", err)
+	}
+
+	tiles := driver.tileset.Tiles()
+	params, _ = json.Marshal(messages.UndoVote{Accept: true})
+	if err := driver.Execute(api.Action{PlayerName: playerNames[voter], Type: messages.TypeUndoVote, Params: params}); err != nil {
+		t.Fatalf("Driver must accept an undo vote, got %v", err)
+	}
+	if len(driver.log.Entries) != 1 {
+		t.Errorf("Undone action must be removed from the log")
+	}
+	if reflect.DeepEqual(driver.tileset.Tiles(), tiles) {
+		t.Errorf("Tiles not drawn yet must be shuffled when undoing an action")
+	}
+	rebuilt, err := driver.Rebuild(1)
+	if err != nil || !reflect.DeepEqual(rebuilt.(*AcquireDriver).snapshot(), driver.snapshot()) {
+		t.Errorf("Rebuilt game must keep the shuffled tiles, got %v", err)
+	}
+}
+
+func TestUnanimousUndoApprovedBySubstitution(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Undo: UndoUnanimous})
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	name := playerNames[current[0]]
+	voter, leaving := (current[0]+1)%3, (current[0]+2)%3
+
+	driver.Execute(api.Action{PlayerName: name, Type: messages.TypePlayTile, Params: params})
+	driver.Execute(api.Action{PlayerName: name, Type: messages.TypeUndo})
+	params, _ = json.Marshal(messages.UndoVote{Accept: true})
+	driver.Execute(api.Action{PlayerName: playerNames[voter], Type: messages.TypeUndoVote, Params: params})
+	if err := driver.SubstitutePlayer(leaving, "greedy"); err != nil {
+		t.Fatalf("Driver must substitute a player while an undo is pending, got %v", err)
+	}
+	entries := driver.log.Entries
+	if len(entries) != 1 || entries[0].Type != replay.TypeSubstituted {
+		t.Errorf("Undone action must be replaced in the log by the substitution, got %v", entries)
+	}
+}
+
+func TestMergePayout(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+	var turns []int
+	nothing := messages.Buy{CorporationsIndexes: map[string]int{}}
+
+	startGame(driver, playerNames, Options{Seed: 1})
+	for _, turn := range []struct {
+		tile  string
+		found int
+		buy   map[string]int
+	}{
+		{tile: "1A", found: -1},
+		{tile: "2A", found: 0, buy: map[string]int{"0": 2}},
+		{tile: "4A", found: -1},
+		{tile: "5A", found: 1, buy: map[string]int{"0": 1}},
+		{tile: "6A", found: -1},
+	} {
+		turns = append(turns, driver.game.CurrentPlayer().Number())
+		playTile(t, driver, turn.tile)
+		if turn.found != -1 {
+			execute(t, driver, messages.TypeFoundCorporation, messages.NewCorp{CorporationIndex: turn.found})
+		}
+		execute(t, driver, messages.TypeBuyStock, messages.Buy{CorporationsIndexes: turn.buy})
+	}
+	first, second := turns[0], turns[1]
+	cash := driver.cash()
+
+	// Sackson, of size 2, is absorbed by Zeta, of size 3
+	playTile(t, driver, "3A")
+	status, _ := driver.Status(first)
+	merge := status.(messages.Status).Merge
+	expected := &messages.MergeData{
+		Acquirer: 1,
+		Defunct:  []messages.DefunctData{{Index: 0, Name: "Sackson", Size: 2, Price: 200}},
+		Bonuses: []messages.BonusData{
+			{Name: playerNames[first], Amount: 1000},
+			{Name: playerNames[second], Amount: 2000},
+		},
+	}
+	sort.Slice(expected.Bonuses, func(i, j int) bool { return expected.Bonuses[i].Name < expected.Bonuses[j].Name })
+	if !reflect.DeepEqual(merge, expected) {
+		t.Errorf("Status must include the merge breakdown %+v, got %+v", expected, merge)
+	}
+	bonuses := map[string]string{}
+	for _, entry := range driver.history {
+		if entry.Key == "game.history.merge_bonus" {
+			bonuses[entry.Arguments["player"]] = entry.Arguments["bonus"]
+		}
+	}
+	if bonuses[playerNames[first]] != "1000" || bonuses[playerNames[second]] != "2000" {
+		t.Errorf("History must include the majority and minority bonuses, got %v", driver.history)
+	}
+
+	// The minority shareholder sells its share, and the majority one trades two and keeps one
+	execute(t, driver, messages.TypeSellTrade, messages.SellTrade{CorporationsIndexes: map[string]messages.SellTradeAmounts{"0": {Sell: 1}}})
+	if paid := driver.players[first].Cash() - cash[first]; paid != 1200 {
+		t.Errorf("Minority shareholder must receive its bonus and the price of the share sold, got %d", paid)
+	}
+	execute(t, driver, messages.TypeSellTrade, messages.SellTrade{CorporationsIndexes: map[string]messages.SellTradeAmounts{"0": {Trade: 2}}})
+	if driver.merge != nil || driver.players[second].Shares(driver.corporations[1]) != 1 {
+		t.Errorf("Merge must finish once every shareholder has decided, with shares traded for the acquirer ones")
+	}
+	execute(t, driver, messages.TypeBuyStock, nothing)
+}
+
+func TestFoundingTileUnplayableWithoutCorporationsLeft(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+	nothing := messages.Buy{CorporationsIndexes: map[string]int{}}
+
+	startGame(driver, playerNames, Options{Seed: 1, Config: GameConfig{Corporations: 2}})
+	for _, turn := range []struct {
+		tile  string
+		found int
+	}{
+		{tile: "1A", found: -1},
+		{tile: "2A", found: 0},
+		{tile: "4A", found: -1},
+		{tile: "5A", found: 1},
+		{tile: "1C", found: -1},
+	} {
+		playTile(t, driver, turn.tile)
+		if turn.found != -1 {
+			execute(t, driver, messages.TypeFoundCorporation, messages.NewCorp{CorporationIndex: turn.found})
+		}
+		execute(t, driver, messages.TypeBuyStock, nothing)
+	}
+
+	// 1D would found a third corporation, but the game only allows two
+	current := driver.game.CurrentPlayer().Number()
+	tl, _ := coordsToTile("1D")
+	driver.game.CurrentPlayer().PickTile(tl)
+	if playable, inHand := driver.tilesData(driver.players[current])["1D"]; !inHand || playable {
+		t.Errorf("Tile founding a corporation must be unplayable when all allowed corporations are active")
+	}
+	legal, _ := driver.legalActions(current)
+	for _, coords := range legal.Tiles {
+		if coords == "1D" {
+			t.Errorf("Tile founding a corporation must not be a legal action when all allowed corporations are active")
+		}
+	}
+	params, _ := json.Marshal(messages.PlayTile{Tile: "1D"})
+	err := driver.Execute(api.Action{PlayerName: playerNames[current], Type: messages.TypePlayTile, Params: params})
+	if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != TileTemporarilyUnplayable {
+		t.Errorf("Driver must reject a tile founding a corporation when all allowed corporations are active, got %v", err)
+	}
+}
+
+func TestCheckTimeoutPlaysForPlayerOutOfTime(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, ActionTime: time.Minute})
+	driver.decisionStart = time.Now().Add(-2 * time.Minute)
+	timedOut, err := driver.CheckTimeout()
+	if err != nil || !timedOut {
+		t.Fatalf("Driver must play for a player out of time, got %v", err)
+	}
+	if len(driver.log.Entries) != 1 {
+		t.Errorf("Decision taken for a player out of time must be recorded")
+	}
+	if driver.history[0].Key != "game.history.timeout" {
+		t.Errorf("Timeout must be added to the history, got %v", driver.history)
+	}
+}
+
+func TestServerHooks(t *testing.T) {
+	var driver api.Driver = New()
+
+	if _, ok := driver.(interface{ CheckTimeout() (bool, error) }); !ok {
+		t.Errorf("Driver must let the server check whether the player in turn ran out of time")
+	}
+	if _, ok := driver.(interface{ PlaySubstitute() (bool, error) }); !ok {
+		t.Errorf("Driver must let the server retry the decision of a bot")
+	}
+}
+
+func TestLegalActionIsAccepted(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1})
+	for i := 0; i < 2; i++ {
+		current, _ := driver.CurrentPlayersNumbers()
+		action, ok := driver.legalAction(current[0])
+		if !ok {
+			t.Fatalf("Driver must find a legal action for the player in turn")
+		}
+		if err := driver.Execute(action); err != nil {
+			t.Errorf("Driver must accept the first legal action, got %v", err)
+		}
+	}
+}
+
+func TestRemovePlayerWithSubstitute(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Substitute: "greedy"})
+	current, _ := driver.CurrentPlayersNumbers()
+	if err := driver.RemovePlayer(current[0]); err != nil {
+		t.Fatalf("Driver must substitute a leaving player, got %v", err)
+	}
+	if len(driver.players) != 3 {
+		t.Errorf("Substituted player must keep its seat")
+	}
+	entries := driver.log.Entries
+	if len(entries) < 3 || entries[1].PlayerNumber != current[0] || driver.isCurrentPlayer(current[0]) {
+		t.Errorf("Bot must play the whole turn of a substituted player right away, got %v", entries)
+	}
+	if played, err := driver.PlaySubstitute(); played || err != nil {
+		t.Errorf("Bot must not play out of its turn, got %v", err)
+	}
+	rebuilt, err := driver.Rebuild(len(driver.log.Entries))
+	if err != nil || !reflect.DeepEqual(rebuilt.(*AcquireDriver).snapshot(), driver.snapshot()) {
+		t.Errorf("Substitutions must be kept when rebuilding a game, got %v", err)
+	}
+	if err := driver.ReclaimPlayer(current[0]); err != nil {
+		t.Errorf("Player must be able to reclaim its seat, got %v", err)
+	}
+}
+
+func TestRejoinRemovedPlayer(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3", 3: "test4"}
+
+	startGame(driver, playerNames, Options{Seed: 1, TimeBank: time.Minute})
+	driver.timeBanks[3] = 20 * time.Second
+	driver.RemovePlayer(3)
+	if err := driver.RejoinPlayer(3, "test3"); err == nil {
+		t.Errorf("Driver must not allow taking a seat with another name")
+	}
+	if err := driver.RejoinPlayer(3, "test4"); err != nil {
+		t.Fatalf("Driver must allow a removed player to rejoin, got %v", err)
+	}
+	if _, exists := driver.players[3]; !exists {
+		t.Errorf("Rejoined player must have its seat back")
+	}
+	if len(driver.log.Entries) != 0 {
+		t.Errorf("Rejoined player departure must be removed from the log")
+	}
+	if driver.timeBanks[3] != 20*time.Second {
+		t.Errorf("Rejoined player must keep the time left in its bank, got %v", driver.timeBanks[3])
+	}
+}
+
+func TestStatusUpdate(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1})
+	current, _ := driver.CurrentPlayersNumbers()
+	update, _ := driver.statusUpdate(current[0], -1)
+	if update.Full == nil {
+		t.Fatalf("Driver must return the full status to a client whose status is unknown")
+	}
+
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params})
+	driver.Status(current[0])
+	update, _ = driver.statusUpdate(current[0], update.Version)
+	if update.Delta == nil || update.Version <= update.Base {
+		t.Errorf("Driver must return the changes since the last status sent, got %+v", update)
+	}
+	if update.Delta != nil && update.Delta.State != "BuyStock" {
+		t.Errorf("Changes must include the new game state, got %+v", update.Delta)
+	}
+}
+
+func TestStatusUpdateInBoardEncoding(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1})
+	driver.SetBoardEncoding(0, messages.BoardPacked)
+	status := messages.Status{Board: map[string]string{"5C": "0", "6C": "0"}}
+	delta := driver.encodedDelta(0, messages.StatusDelta{Board: map[string]string{"6C": "0"}}, status)
+	if delta.Board != nil || delta.Packed == "" {
+		t.Errorf("Board changes must be sent in the encoding chosen by the player, got %+v", delta)
+	}
+}
+
+func TestStatusWithPackedBoard(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	driver.StartGame(playerNames)
+	if err := driver.SetBoardEncoding(0, "binary"); err == nil {
+		t.Errorf("Driver must not accept an unknown board encoding")
+	}
+	driver.SetBoardEncoding(0, messages.BoardPacked)
+	status, _ := driver.Status(0)
+	if status.(messages.Status).Board != nil || len(status.(messages.Status).Packed) != 108 {
+		t.Errorf("Status must include only the packed board for a client which chose it")
+	}
+}
+
+func TestStatusWithPlacements(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Placements: true})
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params})
+
+	status, _ := driver.Status(current[0])
+	expected := []messages.Placement{{Tile: legal.Tiles[0], Name: playerNames[current[0]], Round: 1}}
+	if !reflect.DeepEqual(status.(messages.Status).Placements, expected) {
+		t.Errorf("Status must include the placed tiles, got %v", status.(messages.Status).Placements)
+	}
+	cells := map[string]messages.Placed{legal.Tiles[0]: {Name: playerNames[current[0]], Round: 1}}
+	if !reflect.DeepEqual(status.(messages.Status).Cells, cells) {
+		t.Errorf("Status must include who placed the tile in every cell, got %v", status.(messages.Status).Cells)
+	}
+}
+
+func TestExecuteOutOfTurn(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1})
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+
+	for n, name := range playerNames {
+		if n == current[0] {
+			continue
+		}
+		err := driver.Execute(api.Action{PlayerName: name, Type: messages.TypePlayTile, Params: params})
+		if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != NotYourTurn {
+			t.Errorf("Driver must reject actions from players not in turn, got %v", err)
+		}
+	}
+	err := driver.Execute(api.Action{PlayerName: "rogue", Type: messages.TypePlayTile, Params: params})
+	if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != NonexistentPlayer {
+		t.Errorf("Driver must reject actions from clients not playing the game, got %v", err)
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1})
+	current, _ := driver.CurrentPlayersNumbers()
+	legal, _ := driver.legalActions(current[0])
+	params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+	driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params})
+
+	stats, err := driver.statistics()
+	if err != nil {
+		t.Fatalf("Driver must return the statistics of a game with no hidden information, got %v", err)
+	}
+	for _, pl := range stats.Players {
+		if pl.Name == playerNames[current[0]] && pl.TilesPlayed != 1 {
+			t.Errorf("Statistics must count the tiles played, got %d", pl.TilesPlayed)
+		}
+	}
+	if len(stats.Corporations) != 7 {
+		t.Errorf("Statistics must include every corporation")
+	}
+}
+
+func TestStatisticsWithHiddenInformation(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{HideCash: true})
+	if _, err := driver.Statistics(); err == nil || err.Error() != GameNotOver {
+		t.Errorf("Driver must not return statistics revealing hidden information until the game is over")
+	}
+}
+
+func TestStartGameWithWrongConfig(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	err := startGame(driver, playerNames, Options{Config: GameConfig{MaxBuy: 5}})
+	if driverErr, ok := err.(*DriverError); !ok || driverErr.Code != WrongConfig || driverErr.Param != "buy" {
+		t.Errorf("Driver must reject a configuration out of the allowed ranges, got %v", err)
+	}
+	err = startGame(driver, playerNames, Options{Config: GameConfig{MinPlayers: 4}})
+	if err == nil || err.Error() != WrongPlayersNumber {
+		t.Errorf("Driver must reject starting a game with less players than configured, got %v", err)
+	}
+}
+
+func TestStartGameWithConfig(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Config: GameConfig{StartingCash: 8000, HandSize: 4, MaxBuy: 2}})
+	status, _ := driver.Status(0)
+	if status.(messages.Status).PlayerInfo.Cash != 8000 || len(status.(messages.Status).Hand) != 4 {
+		t.Errorf("Players must start with the configured cash and hand size")
+	}
+	if status.(messages.Status).Rules.MaxBuy != 2 {
+		t.Errorf("Status must include the house rules")
+	}
+}
+
+func TestStartTwoPlayersGame(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2"}
+
+	if err := driver.StartGame(playerNames); err == nil {
+		t.Errorf("Driver must not start a two players game without the house two players variant")
+	}
+	if err := startGame(driver, playerNames, Options{Seed: 1, Variant: VariantHouseTwoPlayers}); err != nil {
+		t.Fatalf("Driver must start a two players game with the house two players variant, got %v", err)
+	}
+	status, _ := driver.Status(0)
+	rivals := status.(messages.Status).RivalsInfo
+	if len(rivals) != 2 || rivals[1].Name != StockMarketName || !rivals[1].Neutral {
+		t.Errorf("Stock Market must be listed as a neutral rival, got %v", rivals)
+	}
+}
+
+func TestRebuildTwoPlayersGame(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2"}
+
+	startGame(driver, playerNames, Options{Seed: 1, Variant: VariantHouseTwoPlayers})
+	for i := 0; i < 4; i++ {
+		current, _ := driver.CurrentPlayersNumbers()
+		if driver.isNeutral(current[0]) {
+			t.Fatalf("Stock Market must never be in turn")
+		}
+		legal, _ := driver.legalActions(current[0])
+		params, _ := json.Marshal(messages.PlayTile{Tile: legal.Tiles[0]})
+		driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypePlayTile, Params: params})
+		driver.Execute(api.Action{PlayerName: playerNames[current[0]], Type: messages.TypeBuyStock, Params: json.RawMessage(`{"cor": {}}`)})
+	}
+	rebuilt, err := driver.Rebuild(len(driver.log.Entries))
+	if err != nil {
+		t.Fatalf("Driver must rebuild a two players game, got %v", err)
+	}
+	if len(driver.log.Entries) != 8 {
+		t.Errorf("Stock Market turns must not be recorded, got %d entries", len(driver.log.Entries))
+	}
+	if !reflect.DeepEqual(rebuilt.(*AcquireDriver).snapshot(), driver.snapshot()) {
+		t.Errorf("Stock Market turns must be played the same way when rebuilding a game")
+	}
+}
+
+func TestStartTeamGame(t *testing.T) {
+	driver := New().(*AcquireDriver)
+	playerNames := map[int]string{0: "test1", 1: "test2", 2: "test3", 3: "test4"}
+
+	if err := startGame(driver, playerNames, Options{Seed: 1, Teams: map[int]string{0: "red", 1: "red", 2: "red", 3: "blue"}}); err == nil {
+		t.Errorf("Driver must not start a game with teams of different sizes")
+	}
+	teams := map[int]string{0: "red", 1: "blue", 2: "red", 3: "blue"}
+	if err := startGame(driver, playerNames, Options{Seed: 1, HideShares: true, Teams: teams}); err != nil {
+		t.Fatalf("Driver must start a game with teams of the same size, got %v", err)
+	}
+	status, _ := driver.Status(0)
+	for _, rival := range status.(messages.Status).RivalsInfo {
+		teammate := rival.Name == "test3"
+		if teammate && (rival.Team != "red" || rival.SharesHidden || len(rival.Hand) == 0) {
+			t.Errorf("Teammate data must not be masked and must include its hand, got %v", rival)
+		}
+		if !teammate && (!rival.SharesHidden || len(rival.Hand) != 0) {
+			t.Errorf("Rival data must be masked and must not include its hand, got %v", rival)
+		}
+	}
+
+	ranking := driver.teamRanking([]messages.FinalPlayerData{
+		{Name: "test1", Total: 6000},
+		{Name: "test2", Total: 8000},
+		{Name: "test3", Total: 5000},
+		{Name: "test4", Total: 1000},
+	})
+	if len(ranking) != 2 || ranking[0].Name != "red" || ranking[0].Total != 11000 || ranking[1].Total != 9000 {
+		t.Errorf("Teams must be ranked by the sum of their players totals, got %v", ranking)
+	}
+}
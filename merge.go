@@ -0,0 +1,139 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/svera/acquire-sackson-driver/internal/corporation"
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+)
+
+// mergingCorporation stores the data of a corporation involved in a merge as it was
+// right before the tile which triggered it was played
+type mergingCorporation struct {
+	corp  acquireInterfaces.Corporation
+	size  int
+	price int
+}
+
+// mergingCorporations returns the corporations that would be merged if the passed tile
+// were played, or nil if playing it would not trigger a merge
+func (b *AcquireDriver) mergingCorporations(tl acquireInterfaces.Tile) []mergingCorporation {
+	merging := []mergingCorporation{}
+	found := map[acquireInterfaces.Corporation]bool{}
+
+	for _, coords := range adjacentCells(tl) {
+		cell := b.game.Board().Cell(coords.Number(), coords.Letter())
+		if cell.Type() != "corporation" {
+			continue
+		}
+		corp := cell.(*corporation.Corporation)
+		if found[corp] {
+			continue
+		}
+		found[corp] = true
+		merging = append(merging, mergingCorporation{
+			corp:  corp,
+			size:  corp.Size(),
+			price: corp.StockPrice(),
+		})
+	}
+	if len(merging) < 2 {
+		return nil
+	}
+	return merging
+}
+
+// cash returns the cash every player has now
+func (b *AcquireDriver) cash() map[int]int {
+	cash := map[int]int{}
+	for n, pl := range b.players {
+		cash[n] = pl.Cash()
+	}
+	return cash
+}
+
+// resolveMerge builds the breakdown of the pending merge once its acquirer is known,
+// adding to the history the defunct corporations, in the order they are processed,
+// and the bonuses paid to their shareholders. Bonuses are whatever the engine paid
+// to every player since it had the passed cash.
+func (b *AcquireDriver) resolveMerge(clientName string, cash map[int]int) {
+	if b.game.GameStateName() == acquireInterfaces.UntieMergeStateName {
+		return
+	}
+
+	var acquirer acquireInterfaces.Corporation
+	defunct := []mergingCorporation{}
+	for _, merging := range b.pendingMerge {
+		if b.game.IsCorporationDefunct(merging.corp) {
+			defunct = append(defunct, merging)
+		} else {
+			acquirer = merging.corp
+		}
+	}
+	b.pendingMerge = nil
+	if acquirer == nil {
+		return
+	}
+	sort.SliceStable(defunct, func(i, j int) bool {
+		if defunct[i].size != defunct[j].size {
+			return defunct[i].size > defunct[j].size
+		}
+		return defunct[i].corp.(*corporation.Corporation).Index() < defunct[j].corp.(*corporation.Corporation).Index()
+	})
+
+	if n, exists := b.playerNumber(clientName); exists {
+		b.playerStats[n].Merges++
+	}
+	acquirerName := acquirer.(*corporation.Corporation).Name()
+	b.merge = &messages.MergeData{
+		Acquirer: acquirer.(*corporation.Corporation).Index(),
+		Defunct:  []messages.DefunctData{},
+		Bonuses:  []messages.BonusData{},
+	}
+	for i, merging := range defunct {
+		corp := merging.corp.(*corporation.Corporation)
+		b.history = append(b.history, messages.I18n{
+			Key: "game.history.merged",
+			Arguments: map[string]string{
+				"player":      clientName,
+				"acquirer":    acquirerName,
+				"corporation": corp.Name(),
+				"size":        strconv.Itoa(merging.size),
+				"order":       strconv.Itoa(i + 1),
+			},
+		})
+		b.merge.Defunct = append(b.merge.Defunct, messages.DefunctData{
+			Index: corp.Index(),
+			Name:  corp.Name(),
+			Size:  merging.size,
+			Price: merging.price,
+		})
+	}
+	for _, n := range b.playerNumbers() {
+		paid := b.players[n].Cash() - cash[n]
+		if paid <= 0 {
+			continue
+		}
+		b.playerStats[n].Bonuses += paid
+		b.merge.Bonuses = append(b.merge.Bonuses, messages.BonusData{Name: b.playerName(n), Amount: paid})
+		b.history = append(b.history, messages.I18n{
+			Key: "game.history.merge_bonus",
+			Arguments: map[string]string{
+				"player": b.playerName(n),
+				"bonus":  strconv.Itoa(paid),
+			},
+		})
+	}
+}
+
+// endMerge drops the breakdown of the last merge once it has finished, that is, when
+// the shareholders of the defunct corporations have decided what to do with their shares
+func (b *AcquireDriver) endMerge() {
+	switch b.game.GameStateName() {
+	case acquireInterfaces.SellTradeStateName, acquireInterfaces.UntieMergeStateName:
+		return
+	}
+	b.merge = nil
+}
@@ -1,20 +1,39 @@
 package main
 
 import (
-	"errors"
 	"strconv"
 
 	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/tileset"
 	acquireInterfaces "github.com/svera/acquire/interfaces"
 	"github.com/svera/acquire/tile"
 )
 
+// TileTemporarilyUnplayable is an error returned when someone tries to play a tile which
+// would found a corporation while all the ones the game allows are active
+const TileTemporarilyUnplayable = "tile_temporarily_unplayable"
+
 func (b *AcquireDriver) playTile(clientName string, params messages.PlayTile) error {
 	var err error
 	var tl acquireInterfaces.Tile
 
 	if tl, err = coordsToTile(params.Tile); err == nil {
+		if b.foundsCorporation(tl) && !b.corporationsLeft() {
+			return newError(TileTemporarilyUnplayable, "til", map[string]string{
+				"tile": params.Tile,
+			})
+		}
+		merging := b.mergingCorporations(tl)
+		cash := b.cash()
 		if err = b.game.PlayTile(tl); err == nil {
+			b.lastTile = tl
+			b.merge = nil
+			b.placements = append(b.placements, messages.Placement{
+				Tile:  params.Tile,
+				Name:  b.currentPlayerName(),
+				Round: b.game.Round(),
+			})
+			b.playerStats[b.game.CurrentPlayer().Number()].TilesPlayed++
 			b.history = append(b.history, messages.I18n{
 				Key: "game.history.played_tile",
 				Arguments: map[string]string{
@@ -22,9 +41,14 @@ func (b *AcquireDriver) playTile(clientName string, params messages.PlayTile) er
 					"tile":   params.Tile,
 				},
 			})
+			if merging != nil {
+				b.pendingMerge = merging
+				b.resolveMerge(clientName, cash)
+				b.endMerge()
+			}
 			return nil
 		}
-		if err.Error() == "no_tiles_available" {
+		if err.Error() == tileset.NoTilesAvailable {
 			b.history = append(b.history, messages.I18n{
 				Key: "game.history.no_tiles_available",
 				Arguments: map[string]string{
@@ -33,14 +57,23 @@ func (b *AcquireDriver) playTile(clientName string, params messages.PlayTile) er
 			})
 			return nil
 		}
+		return newError(err.Error(), "til", map[string]string{
+			"tile": params.Tile,
+		})
 	}
 
 	return err
 }
 
+func tileToCoords(tl acquireInterfaces.Tile) string {
+	return strconv.Itoa(tl.Number()) + tl.Letter()
+}
+
 func coordsToTile(tl string) (acquireInterfaces.Tile, error) {
 	if len(tl) < 2 {
-		return &tile.Tile{}, errors.New("Not a valid tile")
+		return &tile.Tile{}, newError(WrongTile, "til", map[string]string{
+			"tile": tl,
+		})
 	}
 	number, _ := strconv.Atoi(tl[:len(tl)-1])
 	letter := string(tl[len(tl)-1:])
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/sackson-server/api"
+)
+
+// Preview returns what would happen if the passed action were executed by the player
+// in turn, without changing the game. The action is executed on a spare copy of the game,
+// which is kept for the next preview and brought up to date then with the actions executed
+// since. As upstream games cannot be copied, the spare one is only rebuilt from the log
+// when it no longer follows the game, that is, when the action previewed on it is not
+// the one executed next. Hands are not included in the result, as the copy would
+// reveal the tiles to be drawn. The result is a messages.Preview.
+func (b *AcquireDriver) Preview(action api.Action) (interface{}, error) {
+	return b.preview(action)
+}
+
+// preview returns what would happen if the passed action were executed by the player in turn
+func (b *AcquireDriver) preview(action api.Action) (messages.Preview, error) {
+	preview := messages.Preview{}
+
+	if !b.GameStarted() {
+		return preview, errors.New(GameNotStarted)
+	}
+	clone, err := b.spareCopy()
+	if err != nil {
+		return preview, err
+	}
+
+	playerNumber := b.game.CurrentPlayer().Number()
+	before, err := b.playerStatus(playerNumber)
+	if err != nil {
+		return preview, err
+	}
+	err = clone.execute(action)
+	b.spare = clone
+	if err != nil {
+		i18n := actionError(err, action.Type).I18n()
+		preview.Error = &i18n
+		return preview, nil
+	}
+	after, err := clone.playerStatus(playerNumber)
+	if err != nil {
+		return preview, err
+	}
+
+	preview.Valid = true
+	preview.State = clone.game.GameStateName()
+	preview.History = clone.history
+	preview.Delta = messages.Diff(withoutHand(before), withoutHand(after))
+	return preview, nil
+}
+
+// spareCopy returns the spare copy of the game, up to date with it. It is taken away
+// from the driver, which gets it back once the preview is done.
+func (b *AcquireDriver) spareCopy() (*AcquireDriver, error) {
+	clone := b.spare
+	b.spare = nil
+	if clone == nil || !b.log.Follows(clone.log) {
+		var err error
+		if clone, err = rebuildFromLog(b.log, len(b.log.Entries)); err != nil {
+			return nil, err
+		}
+	}
+	for i := len(clone.log.Entries); i < len(b.log.Entries); i++ {
+		if err := b.log.Apply(replayed{clone}, i); err != nil {
+			return nil, err
+		}
+	}
+	return clone, nil
+}
+
+func withoutHand(status messages.Status) messages.Status {
+	status.Hand = nil
+	status.Legal = nil
+	return status
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+)
+
+// RejoinExpired is an error returned when a player tries to rejoin a game which has
+// advanced too much since it left
+const RejoinExpired = "rejoin_expired"
+
+// SeatTaken is an error returned when someone tries to rejoin a game in a seat which is being played
+const SeatTaken = "seat_taken"
+
+// WrongPlayerName is an error returned when someone tries to take a seat registered with another name
+const WrongPlayerName = "wrong_player_name"
+
+// RejoinPlayer gives back its seat to a player who left the game, with the cash, hand,
+// shares and time bank it had. Seats played by bots are simply reclaimed, while removed
+// players are only allowed to rejoin if the game has not advanced more than the RejoinActions
+// option allows and the actions taken since they left did not depend on their absence.
+// In practice, that means a removed player can only rejoin before the turn of its seat
+// comes round again, as that turn was passed to the next player; after that, RejoinExpired
+// is returned. Games in which players are expected to come back should be started
+// with the Substitute option instead.
+func (b *AcquireDriver) RejoinPlayer(number int, name string) error {
+	if !b.GameStarted() {
+		return errors.New(GameNotStarted)
+	}
+	if _, substituted := b.substitutes[number]; substituted {
+		if b.playerName(number) != name {
+			return errors.New(WrongPlayerName)
+		}
+		return b.ReclaimPlayer(number)
+	}
+	if _, exists := b.players[number]; exists {
+		return errors.New(SeatTaken)
+	}
+
+	left := -1
+	for i, entry := range b.log.Entries {
+		if entry.Type == messages.TypeClientOut && entry.PlayerNumber == number {
+			left = i
+		}
+	}
+	if left == -1 {
+		return errors.New(NonexistentPlayer)
+	}
+	if b.log.Entries[left].PlayerName != name {
+		return errors.New(WrongPlayerName)
+	}
+	if b.options.RejoinActions > 0 && len(b.log.Entries)-left-1 > b.options.RejoinActions {
+		return errors.New(RejoinExpired)
+	}
+
+	log := b.log.Without(left)
+	restored, err := rebuildFromLog(log, len(log.Entries))
+	if err != nil {
+		return errors.New(RejoinExpired)
+	}
+	b.replaceGameWith(restored)
+	b.version++
+	b.history = []messages.I18n{
+		{
+			Key: "game.history.player_rejoined",
+			Arguments: map[string]string{
+				"player": name,
+			},
+		},
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/svera/acquire-sackson-driver/internal/replay"
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+	"github.com/svera/sackson-server/api"
+)
+
+// ReplayMismatch is an error returned when a game rebuilt from a log does not start
+// the same way the recorded one did
+const ReplayMismatch = "replay_mismatch"
+
+// maxStartAttempts limits how many times a game is started again when rebuilding it
+// from a log, until upstream chooses the recorded starter player
+const maxStartAttempts = 1000
+
+// Log returns the JSON encoded record of every action accepted since the game started,
+// which can be passed to RebuildFromLog
+func (b *AcquireDriver) Log() ([]byte, error) {
+	if !b.GameStarted() {
+		return nil, errors.New(GameNotStarted)
+	}
+	return json.Marshal(b.log)
+}
+
+// Rebuild returns a new driver with the game as it was after its first n actions
+func (b *AcquireDriver) Rebuild(n int) (api.Driver, error) {
+	if !b.GameStarted() {
+		return nil, errors.New(GameNotStarted)
+	}
+	return rebuildFromLog(b.log, n)
+}
+
+// RebuildFromLog returns a new driver with the game recorded in the passed JSON
+// encoded log as it was after its first n actions
+func RebuildFromLog(data []byte, n int) (api.Driver, error) {
+	var log replay.Log
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, err
+	}
+	return rebuildFromLog(&log, n)
+}
+
+// rebuildFromLog returns a new driver with the game recorded in the passed log
+// as it was after its first n actions
+func rebuildFromLog(log *replay.Log, n int) (*AcquireDriver, error) {
+	game, err := replay.Rebuild(log, n, func() (replay.Game, error) {
+		driver, err := startFromLog(log)
+		if err != nil {
+			return nil, err
+		}
+		return replayed{driver}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	driver := game.(replayed).AcquireDriver
+	for i, entry := range driver.log.Entries {
+		if entry.PlayerNumber != log.Entries[i].PlayerNumber {
+			return nil, errors.New(ReplayMismatch)
+		}
+	}
+	driver.log = log.Until(n)
+	return driver, nil
+}
+
+// replayed adapts a driver to the game interface the replay package rebuilds games with
+type replayed struct {
+	*AcquireDriver
+}
+
+// ShuffleTiles changes the order of the tiles not drawn yet using the passed seed
+func (r replayed) ShuffleTiles(seed int64) {
+	r.shuffleTiles(seed)
+}
+
+// Execute executes the passed action, leaving the turns of the bots to their own entries
+func (r replayed) Execute(action api.Action) error {
+	return r.execute(action)
+}
+
+// RemovePlayer removes a player from the game, leaving the turns of the bots to their own entries
+func (r replayed) RemovePlayer(number int) error {
+	return r.removePlayer(number)
+}
+
+// SubstitutePlayer hands the seat of a player to a bot, leaving its turns to their own entries
+func (r replayed) SubstitutePlayer(number int, level string) error {
+	return r.substitutePlayer(number, level)
+}
+
+// startFromLog starts a game the same way the recorded one was, giving back to every
+// player the hand the engine dealt to it, as upstream deals them ranging over the players
+// map. Upstream chooses the starter player the same way, with no means to set it, so the
+// game is started again until it is the recorded one, which takes as many attempts as
+// players on average.
+func startFromLog(log *replay.Log) (*AcquireDriver, error) {
+	var options Options
+	if err := json.Unmarshal(log.Options, &options); err != nil {
+		return nil, err
+	}
+	options.Seed = log.Seed
+
+	for attempt := 0; attempt < maxStartAttempts; attempt++ {
+		driver := New().(*AcquireDriver)
+		if err := driver.start(log.Players, options, log.Hands); err != nil {
+			return nil, err
+		}
+		if driver.log.Starter == log.Starter {
+			return driver, nil
+		}
+	}
+	return nil, errors.New(ReplayMismatch)
+}
+
+// dealHands gives every player the passed hand, made of the tiles the engine dealt
+func (b *AcquireDriver) dealHands(hands map[int][]string) error {
+	dealt := map[string]acquireInterfaces.Tile{}
+	for _, pl := range b.players {
+		for _, tl := range append([]acquireInterfaces.Tile{}, pl.Tiles()...) {
+			dealt[tileToCoords(tl)] = tl
+			pl.DiscardTile(tl)
+		}
+	}
+	for n, hand := range hands {
+		pl, exists := b.players[n]
+		if !exists {
+			return errors.New(ReplayMismatch)
+		}
+		for _, coords := range hand {
+			tl, exists := dealt[coords]
+			if !exists {
+				return errors.New(ReplayMismatch)
+			}
+			pl.PickTile(tl)
+			delete(dealt, coords)
+		}
+	}
+	if len(dealt) > 0 {
+		return errors.New(ReplayMismatch)
+	}
+	return nil
+}
+
+func (b *AcquireDriver) newLog(clientNames map[int]string, options Options, tiles []acquireInterfaces.Tile) *replay.Log {
+	players := map[int]string{}
+	for n, name := range clientNames {
+		players[n] = name
+	}
+	coords := make([]string, len(tiles))
+	for i, tl := range tiles {
+		coords[i] = tileToCoords(tl)
+	}
+	ser, _ := json.Marshal(options)
+	return replay.New(b.seed, ser, players, coords)
+}
+
+// recordStart stores the hands dealt by the engine and the starter player of a just started game
+func (b *AcquireDriver) recordStart() {
+	for n, pl := range b.players {
+		b.log.Hands[n] = handCoords(pl)
+	}
+	b.log.Starter = b.game.CurrentPlayer().Number()
+}
+
+// handCoords returns the coordinates of the tiles in the hand of the passed player, sorted
+func handCoords(pl acquireInterfaces.Player) []string {
+	hand := []string{}
+	for _, tl := range pl.Tiles() {
+		hand = append(hand, tileToCoords(tl))
+	}
+	sort.Strings(hand)
+	return hand
+}
+
+func (b *AcquireDriver) record(playerNumber int, action api.Action) {
+	b.version++
+	b.log.Record(replay.Entry{
+		PlayerNumber: playerNumber,
+		PlayerName:   action.PlayerName,
+		Type:         action.Type,
+		Params:       action.Params,
+		State:        b.game.GameStateName(),
+		Time:         time.Now(),
+		History:      b.history,
+	})
+}
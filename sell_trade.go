@@ -1,7 +1,6 @@
 package main
 
 import (
-	"errors"
 	"strconv"
 
 	"github.com/svera/acquire-sackson-driver/internal/corporation"
@@ -17,9 +16,11 @@ func (b *AcquireDriver) sellTrade(clientName string, params messages.SellTrade)
 	trade := map[acquireInterfaces.Corporation]int{}
 
 	for corpIndex, operation := range params.CorporationsIndexes {
-		index, _ := strconv.Atoi(corpIndex)
-		if index < 0 || index > 6 {
-			return errors.New(CorporationNotFound)
+		index, err := strconv.Atoi(corpIndex)
+		if err != nil || index < 0 || index > 6 {
+			return newError(CorporationNotFound, "cor", map[string]string{
+				"corporation": corpIndex,
+			})
 		}
 		corp = b.corporations[index]
 		sell[corp] = operation.Sell
@@ -27,7 +28,7 @@ func (b *AcquireDriver) sellTrade(clientName string, params messages.SellTrade)
 	}
 
 	if err = b.game.SellTrade(sell, trade); err != nil {
-		return err
+		return newError(err.Error(), "cor", nil)
 	}
 	for corp, amount := range sell {
 		if amount > 0 {
@@ -53,6 +54,7 @@ func (b *AcquireDriver) sellTrade(clientName string, params messages.SellTrade)
 			})
 		}
 	}
+	b.endMerge()
 
 	return nil
 }
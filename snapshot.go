@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/replay"
+)
+
+// snapshotVersion must be increased every time the snapshot schema changes
+const snapshotVersion = 2
+
+// UnsupportedSnapshot is an error returned when trying to restore a snapshot with an unknown schema version
+const UnsupportedSnapshot = "unsupported_snapshot"
+
+// SnapshotMismatch is an error returned when a restored game is not in the state stored in its snapshot
+const SnapshotMismatch = "snapshot_mismatch"
+
+// snapshot is the JSON representation of a running game. The game is restored
+// executing again the actions stored in its log, the rest of the fields
+// being used to check that the restored game is the one that was saved.
+//
+//   {
+//     "ver": 2, // Schema version
+//     "sta": "BuyStock",
+//     "rnd": 3,
+//     "lst": false,
+//     "brd": {"1A": "empty", ...}, // Same as in status messages
+//     "cor": [...],                // Same as in status messages
+//     "ply": {
+//       "0": {
+//         "nam": "John",
+//         "csh": 6000,
+//         "own": [0, 2, ...],
+//         "hnd": ["1A", "3C", ...],
+//         "bot": "greedy", // Level of the bot playing for the player, if it left the game
+//         "bnk": 540000    // Milliseconds left in the time bank, if the game has time limits
+//       },
+//       ...
+//     },
+//     "til": ["5B", "12I", ...], // Tiles not drawn yet, in drawing order
+//     "log": {...}               // Replay log
+//   }
+type snapshot struct {
+	Version     int                    `json:"ver"`
+	State       string                 `json:"sta"`
+	RoundNumber int                    `json:"rnd"`
+	IsLastRound bool                   `json:"lst"`
+	Board       map[string]string      `json:"brd"`
+	Corps       [7]messages.CorpData   `json:"cor"`
+	Players     map[int]snapshotPlayer `json:"ply"`
+	TileSet     []string               `json:"til"`
+	Log         *replay.Log            `json:"log"`
+}
+
+type snapshotPlayer struct {
+	Name        string   `json:"nam"`
+	Cash        int      `json:"csh"`
+	OwnedShares [7]int   `json:"own"`
+	Hand        []string `json:"hnd"`
+	Bot         string   `json:"bot,omitempty"`
+	Bank        int64    `json:"bnk,omitempty"`
+}
+
+// Snapshot serializes the running game as JSON, so it can be restored later
+func (b *AcquireDriver) Snapshot() ([]byte, error) {
+	if !b.GameStarted() {
+		return nil, errors.New(GameNotStarted)
+	}
+	snap := b.snapshot()
+	snap.Log = b.log
+	return json.Marshal(snap)
+}
+
+// Restore replaces the driver game with the one stored in the passed snapshot
+func (b *AcquireDriver) Restore(data []byte) error {
+	var snap snapshot
+
+	if b.GameStarted() {
+		return errors.New(GameAlreadyStarted)
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+	if snap.Version != snapshotVersion || snap.Log == nil {
+		return errors.New(UnsupportedSnapshot)
+	}
+
+	restored, err := rebuildFromLog(snap.Log, len(snap.Log.Entries))
+	if err != nil {
+		return err
+	}
+	snap.Log = nil
+	for n, pl := range snap.Players {
+		restored.timeBanks[n] = time.Duration(pl.Bank) * time.Millisecond
+	}
+	if !reflect.DeepEqual(restored.snapshot(), snap) {
+		return errors.New(SnapshotMismatch)
+	}
+
+	b.replaceWith(restored)
+	b.seedAIs()
+	return nil
+}
+
+// replaceWith replaces the game of the driver with the one of the passed driver,
+// keeping the bots already created
+func (b *AcquireDriver) replaceWith(driver *AcquireDriver) {
+	ais := b.ais
+	*b = *driver
+	b.ais = ais
+}
+
+// replaceGameWith replaces the game of the driver with the one of the passed driver,
+// rebuilt from the log, keeping the time left, when the current decision started,
+// the bots playing for each player and what has been sent to each client
+func (b *AcquireDriver) replaceGameWith(driver *AcquireDriver) {
+	banks, decisionStart, substitutes := b.timeBanks, b.decisionStart, b.substitutes
+	version, sent, encodings := b.version, b.sent, b.encodings
+	b.replaceWith(driver)
+	b.timeBanks, b.decisionStart, b.substitutes = banks, decisionStart, substitutes
+	b.version, b.sent, b.encodings = version, sent, encodings
+}
+
+// snapshot returns the current state of the game, without its log
+func (b *AcquireDriver) snapshot() snapshot {
+	snap := snapshot{
+		Version:     snapshotVersion,
+		State:       b.game.GameStateName(),
+		RoundNumber: b.game.Round(),
+		IsLastRound: b.game.IsLastRound(),
+		Board:       b.boardOwnership(),
+		Corps:       b.corpsData(),
+		Players:     map[int]snapshotPlayer{},
+		TileSet:     []string{},
+	}
+	for n, pl := range b.players {
+		snap.Players[n] = snapshotPlayer{
+			Name:        b.playerName(n),
+			Cash:        pl.Cash(),
+			OwnedShares: b.playersShares(n),
+			Hand:        handCoords(pl),
+			Bot:         b.substitutes[n].level,
+			Bank:        int64(b.timeBanks[n] / time.Millisecond),
+		}
+	}
+	for _, tl := range b.tileset.Tiles() {
+		snap.TileSet = append(snap.TileSet, tileToCoords(tl))
+	}
+	return snap
+}
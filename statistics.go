@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/svera/acquire-sackson-driver/internal/corporation"
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+)
+
+// GameNotOver is an error returned when someone asks for information only available when the game is over
+const GameNotOver = "game_not_over"
+
+// Statistics returns what every player did during the game and how long every
+// corporation lasted. If cash or shares are hidden, it is only available once the
+// game is over. They are returned as a messages.Statistics.
+func (b *AcquireDriver) Statistics() (interface{}, error) {
+	return b.statistics()
+}
+
+// statistics returns what every player did during the game and how long every corporation lasted
+func (b *AcquireDriver) statistics() (messages.Statistics, error) {
+	stats := messages.Statistics{
+		Players:      []messages.PlayerStatistics{},
+		Corporations: []messages.CorporationStatistics{},
+	}
+
+	if !b.GameStarted() {
+		return stats, errors.New(GameNotStarted)
+	}
+	if (b.options.HideCash || b.options.HideShares) && !b.IsGameOver() {
+		return stats, errors.New(GameNotOver)
+	}
+
+	final := map[string]int{}
+	if b.final != nil {
+		for _, pl := range b.final.Players {
+			final[pl.Name] = pl.Bonuses
+		}
+	}
+	for _, n := range b.playerNumbers() {
+		pl := *b.playerStats[n]
+		pl.Bonuses += final[pl.Name]
+		stats.Players = append(stats.Players, pl)
+	}
+	for i, corp := range b.corporations {
+		stats.Corporations = append(stats.Corporations, messages.CorporationStatistics{
+			Index:     i,
+			Name:      corp.(*corporation.Corporation).Name(),
+			Lifetimes: append([]messages.Lifetime{}, b.corpStats[i]...),
+		})
+	}
+	return stats, nil
+}
+
+// startStatistics resets the statistics of every player and corporation
+func (b *AcquireDriver) startStatistics() {
+	b.playerStats = map[int]*messages.PlayerStatistics{}
+	for n := range b.players {
+		b.playerStats[n] = &messages.PlayerStatistics{Name: b.playerName(n)}
+	}
+	b.corpStats = [7][]messages.Lifetime{}
+}
+
+// hands returns the tiles in the hand of every player
+func (b *AcquireDriver) hands() map[int][]string {
+	hands := map[int][]string{}
+	for n, pl := range b.players {
+		hands[n] = handCoords(pl)
+	}
+	return hands
+}
+
+// updateStatistics counts the tiles discarded since the passed hands were taken,
+// apart from the placed ones, and updates the lifetimes of the corporations
+func (b *AcquireDriver) updateStatistics(hands map[int][]string, placed int) {
+	for n, hand := range hands {
+		if _, exists := b.players[n]; !exists {
+			continue
+		}
+		current := map[string]bool{}
+		for _, coords := range handCoords(b.players[n]) {
+			current[coords] = true
+		}
+		for _, coords := range hand {
+			if current[coords] {
+				continue
+			}
+			if placed > 0 && b.placements[len(b.placements)-placed].Tile == coords {
+				placed--
+				continue
+			}
+			b.playerStats[n].Discarded++
+		}
+	}
+
+	for i, corp := range b.corporations {
+		lifetimes := b.corpStats[i]
+		active := len(lifetimes) > 0 && lifetimes[len(lifetimes)-1].Defunct == 0
+		switch {
+		case corp.Size() > 0 && !active:
+			b.corpStats[i] = append(lifetimes, messages.Lifetime{Founded: b.game.Round(), PeakSize: corp.Size()})
+		case corp.Size() > 0 && corp.Size() > lifetimes[len(lifetimes)-1].PeakSize:
+			lifetimes[len(lifetimes)-1].PeakSize = corp.Size()
+		case corp.Size() == 0 && active:
+			lifetimes[len(lifetimes)-1].Defunct = b.game.Round()
+		}
+	}
+}
@@ -14,28 +14,86 @@ import (
 // Status return a status message with the current status of the game
 // as well as player specific information
 func (b *AcquireDriver) Status(playerNumber int) (interface{}, error) {
-	var msg interface{}
-
 	if !b.GameStarted() {
 		return nil, errors.New(GameNotStarted)
 	}
 
+	status, err := b.playerStatus(playerNumber)
+	if err != nil {
+		return status, err
+	}
+	return b.encoded(playerNumber, status), nil
+}
+
+// playerStatus builds the status of the game as seen by the passed player
+func (b *AcquireDriver) playerStatus(playerNumber int) (messages.Status, error) {
 	playerInfo, rivalsInfo, err := b.playersInfo(playerNumber)
 	if err != nil {
 		return messages.Status{}, err
 	}
-	msg = messages.Status{
+	status := b.status(b.tilesData(b.players[playerNumber]), playerInfo, rivalsInfo, b.historyFor(playerInfo.Name))
+	if b.isCurrentPlayer(playerNumber) && !b.IsGameOver() {
+		legal, _ := b.legalActions(playerNumber)
+		status.Legal = &legal
+	}
+	return status, nil
+}
+
+// SpectatorStatus returns a status message for someone watching the game without
+// playing it, in which all players are listed as rivals and the hand is empty.
+// In omniscient mode, meant for referees, the data of every player includes its hand.
+func (b *AcquireDriver) SpectatorStatus(omniscient bool) (interface{}, error) {
+	if !b.GameStarted() {
+		return nil, errors.New(GameNotStarted)
+	}
+
+	players := []messages.PlayerData{}
+	for _, n := range b.playerNumbers() {
+		data := b.playerData(n)
+		if omniscient {
+			data.Hand = b.tilesData(b.players[n])
+		} else {
+			data = b.masked(data)
+		}
+		players = append(players, data)
+	}
+	if omniscient {
+		return b.status(map[string]bool{}, messages.PlayerData{}, players, b.history), nil
+	}
+	return b.status(map[string]bool{}, messages.PlayerData{}, players, b.historyFor("")), nil
+}
+
+func (b *AcquireDriver) status(hand map[string]bool, playerInfo messages.PlayerData, rivalsInfo []messages.PlayerData, history []messages.I18n) messages.Status {
+	status := messages.Status{
 		Board:       b.boardOwnership(),
 		State:       b.game.GameStateName(),
 		Corps:       b.corpsData(),
-		Hand:        b.tilesData(b.players[playerNumber]),
+		Hand:        hand,
 		PlayerInfo:  playerInfo,
 		RivalsInfo:  rivalsInfo,
 		RoundNumber: b.game.Round(),
 		IsLastRound: b.game.IsLastRound(),
-		History:     b.history,
+		History:     history,
+		Undo:        b.undoData(),
+		Merge:       b.merge,
+		Version:     b.version,
+		Rules:       b.config.rules(),
+	}
+	status.Final = b.final
+	if b.options.Placements {
+		status.Placements = b.placements
+		status.Cells = b.placedCells()
 	}
-	return msg, err
+	return status
+}
+
+// placedCells returns who placed the tile in every board cell and when
+func (b *AcquireDriver) placedCells() map[string]messages.Placed {
+	cells := map[string]messages.Placed{}
+	for _, placement := range b.placements {
+		cells[placement.Tile] = messages.Placed{Name: placement.Name, Round: placement.Round}
+	}
+	return cells
 }
 
 func (b *AcquireDriver) boardOwnership() map[string]string {
@@ -60,6 +118,8 @@ func (b *AcquireDriver) corpsData() [7]messages.CorpData {
 	for i, corp := range b.corporations {
 		data[i] = messages.CorpData{
 			Name:            corp.(*corporation.Corporation).Name(),
+			ID:              corp.(*corporation.Corporation).ID(),
+			Tier:            corp.(*corporation.Corporation).Tier().String(),
 			Price:           corp.StockPrice(),
 			MajorityBonus:   corp.MajorityBonus(),
 			MinorityBonus:   corp.MinorityBonus(),
@@ -78,11 +138,9 @@ func (b *AcquireDriver) corpsData() [7]messages.CorpData {
 
 func (b *AcquireDriver) tilesData(pl acquireInterfaces.Player) map[string]bool {
 	hnd := map[string]bool{}
-	var coords string
 
 	for _, tl := range pl.Tiles() {
-		coords = strconv.Itoa(tl.Number()) + tl.Letter()
-		hnd[coords] = b.game.IsTilePlayable(tl)
+		hnd[tileToCoords(tl)] = b.isTilePlayable(tl)
 	}
 	return hnd
 }
@@ -96,22 +154,76 @@ func (b *AcquireDriver) playersInfo(n int) (messages.PlayerData, []messages.Play
 		err = errors.New(NonexistentPlayer)
 	}
 
-	for i, p := range b.players {
-		if n != i {
-			rivals = append(rivals, messages.PlayerData{
-				Name:        p.(*player.Player).Name(),
-				Cash:        p.Cash(),
-				OwnedShares: b.playersShares(i),
-				InTurn:      b.isCurrentPlayer(i),
-			})
-		} else {
-			ply = messages.PlayerData{
-				Name:        p.(*player.Player).Name(),
-				Cash:        p.Cash(),
-				OwnedShares: b.playersShares(n),
-				InTurn:      b.isCurrentPlayer(n),
-			}
+	for _, i := range b.playerNumbers() {
+		switch {
+		case n == i:
+			ply = b.playerData(n)
+		case b.teammates(n, i):
+			data := b.playerData(i)
+			data.Hand = b.tilesData(b.players[i])
+			rivals = append(rivals, data)
+		default:
+			rivals = append(rivals, b.masked(b.playerData(i)))
 		}
 	}
 	return ply, rivals, err
 }
+
+// playerData returns the public information of a player
+func (b *AcquireDriver) playerData(n int) messages.PlayerData {
+	return messages.PlayerData{
+		Name:        b.players[n].(*player.Player).Name(),
+		Cash:        b.players[n].Cash(),
+		OwnedShares: b.playersShares(n),
+		InTurn:      b.isCurrentPlayer(n),
+		Clock:       b.clockData(n),
+		Bot:         b.substitutes[n].level,
+		Neutral:     b.isNeutral(n),
+		Team:        b.options.Teams[n],
+	}
+}
+
+// masked hides the cash and owned shares of a player from the rest, if the game
+// options require so, until the game is over
+func (b *AcquireDriver) masked(data messages.PlayerData) messages.PlayerData {
+	if b.IsGameOver() || data.Neutral {
+		return data
+	}
+	if b.options.HideCash {
+		data.Cash = 0
+		data.CashHidden = true
+	}
+	if b.options.HideShares {
+		data.OwnedShares = [7]int{}
+		data.SharesHidden = true
+	}
+	return data
+}
+
+// historyFor returns the history as seen by the passed player (or by a spectator, if
+// the name is empty). When shares are hidden, the amount of stock bought, sold or
+// traded by rivals (but the Stock Market and teammates) is removed from their entries,
+// whose keys get the "_hidden" suffix.
+func (b *AcquireDriver) historyFor(receiver string) []messages.I18n {
+	if !b.options.HideShares || b.IsGameOver() {
+		return b.history
+	}
+
+	history := make([]messages.I18n, 0, len(b.history))
+	for _, entry := range b.history {
+		player := entry.Arguments["player"]
+		neutral := b.options.Variant == VariantHouseTwoPlayers && player == StockMarketName
+		teammate := receiver != "" && b.teamOf(player) != "" && b.teamOf(player) == b.teamOf(receiver)
+		if _, hasAmount := entry.Arguments["amount"]; hasAmount && player != receiver && !neutral && !teammate {
+			arguments := map[string]string{}
+			for k, v := range entry.Arguments {
+				if k != "amount" {
+					arguments[k] = v
+				}
+			}
+			entry = messages.I18n{Key: entry.Key + "_hidden", Arguments: arguments}
+		}
+		history = append(history, entry)
+	}
+	return history
+}
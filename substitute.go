@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/svera/acquire-sackson-driver/internal/bots"
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/replay"
+	"github.com/svera/sackson-server/api"
+)
+
+// NotSubstituted is an error returned when someone tries to reclaim a seat not played by a bot
+const NotSubstituted = "not_substituted"
+
+// substitute stores the bot playing on behalf of a player who left the game
+type substitute struct {
+	level string
+	ai    api.AI
+}
+
+// SubstitutePlayer hands the seat of the passed player to a bot of the passed level,
+// keeping its cash, hand and shares, instead of removing it from the game. The bot
+// takes its turn right away if the player was in turn.
+func (b *AcquireDriver) SubstitutePlayer(number int, level string) error {
+	if err := b.substitutePlayer(number, level); err != nil {
+		return err
+	}
+	b.playSubstitutes()
+	return nil
+}
+
+// substitutePlayer hands the seat of the passed player to a bot of the passed level,
+// without playing the turns of the seats played by bots
+func (b *AcquireDriver) substitutePlayer(number int, level string) error {
+	if !b.GameStarted() {
+		return errors.New(GameNotStarted)
+	}
+	if _, exists := b.players[number]; !exists {
+		return errors.New(NonexistentPlayer)
+	}
+	if err := b.substitute(number, level); err != nil {
+		return err
+	}
+	// Bots do not vote, so the pending undo may have been approved by everyone else,
+	// in which case the last action is undone before recording the substitution
+	history := []messages.I18n{}
+	if b.pendingUndo != nil {
+		if err := b.settleUndo(); err != nil {
+			return err
+		}
+		if b.pendingUndo == nil {
+			history = b.history
+		}
+	}
+	b.history = append(history, []messages.I18n{
+		{
+			Key: "game.history.player_left",
+			Arguments: map[string]string{
+				"player": b.playerName(number),
+			},
+		},
+		{
+			Key: "game.history.player_substituted",
+			Arguments: map[string]string{
+				"player": b.playerName(number),
+				"bot":    level,
+			},
+		},
+	}...)
+	params, _ := json.Marshal(replay.Substitution{Level: level})
+	b.record(number, api.Action{PlayerName: b.playerName(number), Type: replay.TypeSubstituted, Params: params})
+	return nil
+}
+
+// substitute creates a bot of the passed level to play for the passed player
+func (b *AcquireDriver) substitute(number int, level string) error {
+	ai, err := bots.Create(bots.Params{Level: level}, b.botSeed())
+	if err != nil {
+		return err
+	}
+	b.substitutes[number] = substitute{level: level, ai: ai}
+	return nil
+}
+
+// ReclaimPlayer gives back the control of a seat played by a bot to its player
+func (b *AcquireDriver) ReclaimPlayer(number int) error {
+	if !b.GameStarted() {
+		return errors.New(GameNotStarted)
+	}
+	if _, substituted := b.substitutes[number]; !substituted {
+		return errors.New(NotSubstituted)
+	}
+	delete(b.substitutes, number)
+	b.history = []messages.I18n{
+		{
+			Key: "game.history.player_reclaimed",
+			Arguments: map[string]string{
+				"player": b.playerName(number),
+			},
+		},
+	}
+	b.record(number, api.Action{PlayerName: b.playerName(number), Type: replay.TypeReclaimed})
+	return nil
+}
+
+// PlaySubstitute plays the current decision on behalf of the player in turn if its
+// seat is played by a bot, and returns true if so, followed by the turns of any other
+// bots until a player has to decide. Execute, RemovePlayer and SubstitutePlayer already
+// play them, so the server only needs it to retry a decision which could not be taken,
+// whose error it returns. As it is not part of api.Driver, the server reaches it
+// asserting the driver to interface{ PlaySubstitute() (bool, error) }.
+func (b *AcquireDriver) PlaySubstitute() (bool, error) {
+	b.history = nil
+	played, err := b.playSubstitute()
+	if played {
+		b.playSubstitutes()
+	}
+	return played, err
+}
+
+// playSubstitutes plays the turns of the seats played by bots for as long as one of
+// them is in turn, keeping the history of all of them. A game only bots are left in
+// is not played on. If a bot cannot take its decision, it is added to the history
+// and the game waits for the server to call PlaySubstitute.
+func (b *AcquireDriver) playSubstitutes() {
+	history := b.history
+	for len(b.humans()) > 0 {
+		n := b.game.CurrentPlayer().Number()
+		played, err := b.playSubstitute()
+		if err != nil {
+			history = append(history, messages.I18n{
+				Key: "game.history.substitute_failed",
+				Arguments: map[string]string{
+					"player": b.playerName(n),
+				},
+			})
+			break
+		}
+		if !played {
+			break
+		}
+		history = append(history, b.history...)
+	}
+	b.history = history
+}
+
+// playSubstitute plays the current decision on behalf of the player in turn if its
+// seat is played by a bot, and returns true if so. If the decision of the bot is
+// rejected, the first legal action is taken instead, and if there is none the
+// error is returned.
+func (b *AcquireDriver) playSubstitute() (bool, error) {
+	if !b.GameStarted() || b.IsGameOver() || b.pendingUndo != nil {
+		return false, nil
+	}
+	n := b.game.CurrentPlayer().Number()
+	sub, substituted := b.substitutes[n]
+	if !substituted {
+		return false, nil
+	}
+
+	action, err := b.botAction(sub.ai, n)
+	if err == nil {
+		err = b.execute(action)
+	}
+	if err != nil {
+		fallback, ok := b.legalAction(n)
+		if !ok {
+			return false, err
+		}
+		if err = b.execute(fallback); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// humans returns the numbers of the seats played by people, in ascending order
+func (b *AcquireDriver) humans() []int {
+	humans := []int{}
+	for _, n := range b.playerNumbers() {
+		if _, substituted := b.substitutes[n]; !substituted && !b.isNeutral(n) {
+			humans = append(humans, n)
+		}
+	}
+	return humans
+}
+
+// botAction returns the decision the passed bot takes in place of the passed player
+func (b *AcquireDriver) botAction(ai api.AI, playerNumber int) (api.Action, error) {
+	status, err := b.playerStatus(playerNumber)
+	if err != nil {
+		return api.Action{}, err
+	}
+	ser, _ := json.Marshal(status)
+	if err = ai.FeedGameStatus(ser); err != nil {
+		return api.Action{}, err
+	}
+	action := ai.Play()
+	action.PlayerName = b.playerName(playerNumber)
+	return action, nil
+}
+
+// botSeed returns the seed for a bot created during the game. Deriving it from the
+// number of recorded actions keeps its decisions reproducible, and taking even offsets
+// from the game seed keeps it apart from the ones given by aiSeed.
+func (b *AcquireDriver) botSeed() int64 {
+	return b.seed + 2*int64(len(b.log.Entries)) + 2
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+)
+
+// WrongTeams is an error returned when a game is started with teams which are not
+// all of the same size, or which leave players out of them
+const WrongTeams = "wrong_teams"
+
+// validateTeams checks that every player belongs to a team and that there are
+// at least two teams, all of them with the same number of players
+func validateTeams(clientNames map[int]string, options Options) error {
+	if len(options.Teams) == 0 {
+		return nil
+	}
+	if options.Variant != "" || len(options.Teams) != len(clientNames) {
+		return errors.New(WrongTeams)
+	}
+	sizes := map[string]int{}
+	for n := range clientNames {
+		team, exists := options.Teams[n]
+		if !exists || team == "" {
+			return errors.New(WrongTeams)
+		}
+		sizes[team]++
+	}
+	if len(sizes) < 2 {
+		return errors.New(WrongTeams)
+	}
+	for _, size := range sizes {
+		if size != len(clientNames)/len(sizes) || size*len(sizes) != len(clientNames) {
+			return errors.New(WrongTeams)
+		}
+	}
+	return nil
+}
+
+// teammates returns true if both passed players are in the same team
+func (b *AcquireDriver) teammates(playerNumber int, other int) bool {
+	team, exists := b.options.Teams[playerNumber]
+	return exists && team == b.options.Teams[other]
+}
+
+// teamOf returns the team of the player with the passed name, or an empty string
+// if there are no teams or there is no such player
+func (b *AcquireDriver) teamOf(name string) string {
+	if n, exists := b.playerNumber(name); exists {
+		return b.options.Teams[n]
+	}
+	return ""
+}
+
+// teamRanking adds up the totals of the players of every team and ranks the teams
+func (b *AcquireDriver) teamRanking(players []messages.FinalPlayerData) []messages.RankData {
+	totals := map[string]int{}
+	for _, pl := range players {
+		if team := b.teamOf(pl.Name); team != "" {
+			totals[team] += pl.Total
+		}
+	}
+	teams := make([]messages.FinalPlayerData, 0, len(totals))
+	for team, total := range totals {
+		teams = append(teams, messages.FinalPlayerData{Name: team, Total: total})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+	return rank(teams)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/sackson-server/api"
+)
+
+// These are the policies which define when players can undo their last action
+const (
+	// UndoNever does not allow undoing actions
+	UndoNever = "never"
+	// UndoTurn allows the player in turn to undo its last action, as long as
+	// the turn has not passed to another player
+	UndoTurn = "turn"
+	// UndoUnanimous allows the author of the last action to undo it if
+	// all the other players approve it
+	UndoUnanimous = "unanimous"
+)
+
+// UndoNotAllowed is an error returned when someone tries to undo an action the game options do not allow to undo
+const UndoNotAllowed = "undo_not_allowed"
+
+// UndoPending is an error returned when someone tries to play while an undo is waiting for approval
+const UndoPending = "undo_pending"
+
+// NoUndoPending is an error returned when someone votes an undo that has not been requested
+const NoUndoPending = "no_undo_pending"
+
+// undoRequest stores an undo waiting for the approval of all players. Seats played
+// by bots and the Stock Market do not vote, as they cannot.
+type undoRequest struct {
+	player    int
+	approvals map[int]bool
+}
+
+// voters returns the numbers of the players who have not approved the undo yet
+func (u *undoRequest) voters(numbers []int) []int {
+	voters := []int{}
+	for _, n := range numbers {
+		if !u.approvals[n] {
+			voters = append(voters, n)
+		}
+	}
+	return voters
+}
+
+func (b *AcquireDriver) undo(action api.Action) error {
+	if !b.GameStarted() {
+		return errors.New(GameNotStarted)
+	}
+	if action.Type == messages.TypeUndo {
+		return b.requestUndo(action.PlayerName)
+	}
+	var parsed messages.UndoVote
+	if err := json.Unmarshal(action.Params, &parsed); err != nil {
+		return err
+	}
+	return b.voteUndo(action.PlayerName, parsed)
+}
+
+func (b *AcquireDriver) requestUndo(clientName string) error {
+	requester, exists := b.playerNumber(clientName)
+	if !exists {
+		return newError(NonexistentPlayer, "", nil)
+	}
+	if len(b.log.Entries) == 0 {
+		return newError(UndoNotAllowed, "", nil)
+	}
+	last := b.log.Entries[len(b.log.Entries)-1]
+	if !last.IsAction() || last.PlayerNumber != requester {
+		return newError(UndoNotAllowed, "", nil)
+	}
+
+	switch b.options.Undo {
+	case UndoTurn:
+		if !b.isCurrentPlayer(requester) {
+			return newError(UndoNotAllowed, "", nil)
+		}
+		return b.rollback()
+	case UndoUnanimous:
+		b.pendingUndo = &undoRequest{
+			player:    requester,
+			approvals: map[int]bool{requester: true},
+		}
+		b.history = append(b.history, messages.I18n{
+			Key: "game.history.undo_requested",
+			Arguments: map[string]string{
+				"player": clientName,
+			},
+		})
+		return b.settleUndo()
+	}
+	return newError(UndoNotAllowed, "", nil)
+}
+
+func (b *AcquireDriver) voteUndo(clientName string, vote messages.UndoVote) error {
+	if b.pendingUndo == nil {
+		return newError(NoUndoPending, "", nil)
+	}
+	voter, exists := b.playerNumber(clientName)
+	if !exists {
+		return newError(NonexistentPlayer, "", nil)
+	}
+
+	if !vote.Accept {
+		b.pendingUndo = nil
+		b.history = append(b.history, messages.I18n{
+			Key: "game.history.undo_rejected",
+			Arguments: map[string]string{
+				"player": clientName,
+			},
+		})
+		return nil
+	}
+	b.pendingUndo.approvals[voter] = true
+	return b.settleUndo()
+}
+
+// undoVoters returns the numbers of the players who still have to approve the pending undo
+func (b *AcquireDriver) undoVoters() []int {
+	return b.pendingUndo.voters(b.humans())
+}
+
+// settleUndo undoes the last action if the pending undo has been approved by everyone
+func (b *AcquireDriver) settleUndo() error {
+	if b.pendingUndo == nil || len(b.undoVoters()) > 0 {
+		return nil
+	}
+	return b.rollback()
+}
+
+// cancelUndo drops the pending undo, if any, as the last action is no longer the one
+// whose undo was requested
+func (b *AcquireDriver) cancelUndo() {
+	if b.pendingUndo == nil {
+		return
+	}
+	b.pendingUndo = nil
+	b.history = append(b.history, messages.I18n{
+		Key: "game.history.undo_cancelled",
+	})
+}
+
+// rollback rebuilds the game as it was before the last action, keeping the history of
+// the action before it. The tiles not drawn yet are shuffled again, so the ones drawn by
+// the undone action are not known in advance.
+func (b *AcquireDriver) rollback() error {
+	last := b.log.Entries[len(b.log.Entries)-1]
+	restored, err := rebuildFromLog(b.log, len(b.log.Entries)-1)
+	if err != nil {
+		return err
+	}
+	b.replaceGameWith(restored)
+	seed := time.Now().UnixNano()
+	b.shuffleTiles(seed)
+	b.log.Shuffle(seed)
+	b.history = append(b.history, messages.I18n{
+		Key: "game.history.undone",
+		Arguments: map[string]string{
+			"player": last.PlayerName,
+		},
+	})
+	return nil
+}
+
+// shuffleTiles changes the order of the tiles not drawn yet using the passed seed
+func (b *AcquireDriver) shuffleTiles(seed int64) {
+	b.tileset.Shuffle(seed)
+}
+
+func (b *AcquireDriver) undoData() *messages.UndoData {
+	if b.pendingUndo == nil {
+		return nil
+	}
+	data := &messages.UndoData{
+		Name:      b.playerName(b.pendingUndo.player),
+		Approvals: []string{},
+	}
+	for _, n := range b.playerNumbers() {
+		if b.pendingUndo.approvals[n] {
+			data.Approvals = append(data.Approvals, b.playerName(n))
+		}
+	}
+	return data
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"errors"
+	"strconv"
 
 	"github.com/svera/acquire-sackson-driver/internal/corporation"
 	"github.com/svera/acquire-sackson-driver/internal/messages"
@@ -9,12 +9,17 @@ import (
 
 func (b *AcquireDriver) untieMerge(clientName string, params messages.UntieMerge) error {
 	if params.CorporationIndex < 0 || params.CorporationIndex > 6 {
-		return errors.New(CorporationNotFound)
+		return newError(CorporationNotFound, "cor", map[string]string{
+			"corporation": strconv.Itoa(params.CorporationIndex),
+		})
 	}
 
 	corp := b.corporations[params.CorporationIndex]
+	cash := b.cash()
 	if err := b.game.UntieMerge(corp); err != nil {
-		return err
+		return newError(err.Error(), "cor", map[string]string{
+			"corporation": corp.(*corporation.Corporation).Name(),
+		})
 	}
 	b.history = append(b.history, messages.I18n{
 		Key: "game.history.untied_merge",
@@ -23,6 +28,10 @@ func (b *AcquireDriver) untieMerge(clientName string, params messages.UntieMerge
 			"corporation": corp.(*corporation.Corporation).Name(),
 		},
 	})
+	if b.pendingMerge != nil {
+		b.resolveMerge(clientName, cash)
+		b.endMerge()
+	}
 
 	return nil
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+)
+
+// StatusUpdate returns the changes in the status of the passed player since the passed
+// version, which must be the one of the last update returned for it. If it is not, or
+// placed tiles have been undone since then, the full status is returned instead. Changes
+// in the board come in the encoding chosen by the player. The update is returned as a messages.Update.
+func (b *AcquireDriver) StatusUpdate(playerNumber int, version int) (interface{}, error) {
+	return b.statusUpdate(playerNumber, version)
+}
+
+// statusUpdate returns the changes in the status of the passed player since the passed version
+func (b *AcquireDriver) statusUpdate(playerNumber int, version int) (messages.Update, error) {
+	update := messages.Update{}
+
+	if !b.GameStarted() {
+		return update, errors.New(GameNotStarted)
+	}
+	previous, known := b.sent[playerNumber]
+	status, err := b.playerStatus(playerNumber)
+	if err != nil {
+		return update, err
+	}
+	b.sent[playerNumber] = status
+
+	update.Version = status.Version
+	if !known || previous.Version != version || len(status.Placements) < len(previous.Placements) {
+		full := b.encoded(playerNumber, status)
+		update.Full = &full
+		return update, nil
+	}
+	delta := b.encodedDelta(playerNumber, messages.Diff(previous, status), status)
+	update.Base = version
+	update.Delta = &delta
+	return update, nil
+}
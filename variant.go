@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/svera/acquire-sackson-driver/internal/messages"
+	"github.com/svera/acquire-sackson-driver/internal/rules"
+	acquireInterfaces "github.com/svera/acquire/interfaces"
+)
+
+// VariantHouseTwoPlayers is a house variant for two players, inspired by the official
+// one but not following it. Upstream needs three players, so a neutral Stock Market takes
+// the third seat, and its turns are played right away: it places the tile which changes
+// the board the least, which may found corporations or trigger merges as any other tile,
+// keeps its shares when they become defunct and, instead of buying stock, draws a random
+// share, competing with the players for majority bonuses. In the official variant, the
+// dummy shareholder does not play tiles.
+const VariantHouseTwoPlayers = "house_two_players"
+
+// StockMarketName is the name of the neutral shareholder in the house two players variant
+const StockMarketName = "Stock Market"
+
+// NeutralStuck is an error returned when the Stock Market has no legal action to pass its turn with
+const NeutralStuck = "neutral_stuck"
+
+// WrongVariant is an error returned when a game is started with an unknown rules variant
+// or with a number of players the variant does not allow
+const WrongVariant = "wrong_variant"
+
+// seats returns the names of the players taking part in the game, neutral ones included
+func (b *AcquireDriver) seats(clientNames map[int]string, variant string) (map[int]string, error) {
+	switch variant {
+	case "":
+		return clientNames, nil
+	case VariantHouseTwoPlayers:
+		if len(clientNames) != 2 {
+			return nil, errors.New(WrongVariant)
+		}
+		seats := map[int]string{}
+		market := 0
+		for n, name := range clientNames {
+			if name == StockMarketName {
+				return nil, errors.New(WrongVariant)
+			}
+			if n >= market {
+				market = n + 1
+			}
+			seats[n] = name
+		}
+		seats[market] = StockMarketName
+		return seats, nil
+	}
+	return nil, errors.New(WrongVariant)
+}
+
+// isNeutral returns true if the passed player is not a real one, but the Stock Market
+func (b *AcquireDriver) isNeutral(playerNumber int) bool {
+	return b.options.Variant == VariantHouseTwoPlayers && b.playerName(playerNumber) == StockMarketName
+}
+
+// passNeutrals plays right away the turns the engine gives to the Stock Market, which
+// only holds a seat because the engine needs three players, so nobody ever waits for it.
+// It places the tile which changes the board the least, keeps its shares when they become
+// defunct and, instead of buying stock, draws a random share. As all of it follows
+// from the game state and seed, it is not recorded in the log, but played again
+// when the game is rebuilt.
+func (b *AcquireDriver) passNeutrals() error {
+	for !b.IsGameOver() && b.isNeutral(b.game.CurrentPlayer().Number()) {
+		n := b.game.CurrentPlayer().Number()
+		action, ok := b.legalAction(n)
+		if !ok {
+			return errors.New(NeutralStuck)
+		}
+		switch b.game.GameStateName() {
+		case acquireInterfaces.PlayTileStateName:
+			legal, _ := b.legalActions(n)
+			action.Params, _ = json.Marshal(messages.PlayTile{Tile: b.quietestTile(legal.Tiles)})
+		case acquireInterfaces.SellTradeStateName:
+			action.Params, _ = json.Marshal(messages.SellTrade{CorporationsIndexes: map[string]messages.SellTradeAmounts{}})
+		case acquireInterfaces.BuyStockStateName:
+			action.Params, _ = json.Marshal(b.drawShare(n))
+		}
+		holdings := b.holdings()
+		if err := b.apply(StockMarketName, action); err != nil {
+			return err
+		}
+		b.settle(holdings)
+	}
+	return nil
+}
+
+// quietestTile returns the one of the passed tiles with the fewest tiles around it,
+// the first one in coordinates order if there are several
+func (b *AcquireDriver) quietestTile(tiles []string) string {
+	sorted := append([]string{}, tiles...)
+	sort.Strings(sorted)
+	quietest, fewest := "", -1
+	for _, coords := range sorted {
+		neighbours := 0
+		for _, c := range rules.ParseCell(coords).Adjacent() {
+			if b.game.Board().Cell(c.Number, rules.Letters[c.Letter:c.Letter+1]).Type() != "empty" {
+				neighbours++
+			}
+		}
+		if fewest == -1 || neighbours < fewest {
+			quietest, fewest = coords, neighbours
+		}
+	}
+	return quietest
+}
+
+// drawShare returns the purchase of a random share of an active corporation with stock
+// left, as drawn by the passed neutral player. Shares are free for it, so it is given the
+// money the engine charges for them.
+func (b *AcquireDriver) drawShare(n int) messages.Buy {
+	buy := messages.Buy{CorporationsIndexes: map[string]int{}}
+	available := []int{}
+	for i, corp := range b.corporations[:b.config.Corporations] {
+		if corp.Size() > 0 && corp.Stock() > 0 {
+			available = append(available, i)
+		}
+	}
+	if len(available) == 0 {
+		return buy
+	}
+	index := available[b.market.Intn(len(available))]
+	b.players[n].AddCash(b.corporations[index].StockPrice())
+	buy.CorporationsIndexes[strconv.Itoa(index)] = 1
+	return buy
+}